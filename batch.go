@@ -0,0 +1,100 @@
+package go_sdk
+
+import (
+	"context"
+	"sync"
+)
+
+const DefaultBatchConcurrency = 4
+
+type BatchOptions struct {
+	// Concurrency is the number of workers sending in parallel, DefaultBatchConcurrency when not positive.
+	Concurrency int
+	// IdempotencyKey, when set, returns the idempotency key of a message, which allows
+	// the retry policy to retry its POST request.
+	IdempotencyKey func(index int, msg EmailMessage) string
+}
+
+type EmailResult struct {
+	Index    int
+	Message  EmailMessage
+	Response []byte
+	Err      error
+}
+
+type BatchReport struct {
+	Results   []EmailResult
+	Succeeded int
+	Failed    int
+}
+
+func (r BatchReport) Failures() []EmailResult {
+	var failures []EmailResult
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failures = append(failures, result)
+		}
+	}
+
+	return failures
+}
+
+// SendEmails sends the messages over a pool of workers, going through the rate limiter and
+// retry policy like any other call. Results are reported in the order of msgs; messages not
+// sent before ctx is done carry the context error.
+func (sdk *SendiosSdk) SendEmails(ctx context.Context, msgs []EmailMessage, opts BatchOptions) BatchReport {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+
+	results := make([]EmailResult, len(msgs))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				results[index] = sdk.sendBatchItem(ctx, index, msgs[index], opts)
+			}
+		}()
+	}
+
+	for index := range msgs {
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+
+	report := BatchReport{Results: results}
+	for _, result := range results {
+		if result.Err != nil {
+			report.Failed++
+		} else {
+			report.Succeeded++
+		}
+	}
+
+	return report
+}
+
+func (sdk *SendiosSdk) sendBatchItem(ctx context.Context, index int, msg EmailMessage, opts BatchOptions) EmailResult {
+	result := EmailResult{Index: index, Message: msg}
+
+	if err := ctx.Err(); err != nil {
+		result.Err = err
+		return result
+	}
+
+	if opts.IdempotencyKey != nil {
+		if key := opts.IdempotencyKey(index, msg); key != "" {
+			ctx = ContextWithIdempotencyKey(ctx, key)
+		}
+	}
+
+	result.Response, result.Err = sdk.Send(ctx, msg)
+
+	return result
+}
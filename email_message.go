@@ -0,0 +1,152 @@
+package go_sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sendios/go-sdk/internal"
+)
+
+var ErrInvalidEmailMessage = errors.New("invalid email message")
+
+// EmailMessage describes a single email for Send. Data may be any json marshalable value,
+// it is encrypted and sent as the template data.
+type EmailMessage struct {
+	ClientId  int
+	TypeId    int
+	Category  int
+	ProjectId int
+	Email     string
+	User      map[string]string
+	Data      interface{}
+	Meta      map[string]string
+}
+
+func NewEmailMessage(email string) EmailMessage {
+	return EmailMessage{Email: email}
+}
+
+func (m EmailMessage) WithClient(clientId int) EmailMessage {
+	m.ClientId = clientId
+
+	return m
+}
+
+func (m EmailMessage) WithProject(projectId int) EmailMessage {
+	m.ProjectId = projectId
+
+	return m
+}
+
+// WithType sets the category, System or Trigger, and the email type id.
+func (m EmailMessage) WithType(category int, typeId int) EmailMessage {
+	m.Category = category
+	m.TypeId = typeId
+
+	return m
+}
+
+func (m EmailMessage) WithUser(user map[string]string) EmailMessage {
+	m.User = user
+
+	return m
+}
+
+func (m EmailMessage) WithData(data interface{}) EmailMessage {
+	m.Data = data
+
+	return m
+}
+
+func (m EmailMessage) WithMeta(meta map[string]string) EmailMessage {
+	m.Meta = meta
+
+	return m
+}
+
+func (m EmailMessage) Validate() error {
+	if _, err := getRoute(m.Category); err != nil {
+		return fmt.Errorf("%w: category must be System or Trigger, got %d", ErrInvalidEmailMessage, m.Category)
+	}
+
+	if strings.TrimSpace(m.Email) == "" {
+		return fmt.Errorf("%w: email is empty", ErrInvalidEmailMessage)
+	}
+
+	if m.ProjectId <= 0 {
+		return fmt.Errorf("%w: project id must be positive, got %d", ErrInvalidEmailMessage, m.ProjectId)
+	}
+
+	return nil
+}
+
+// Send validates and sends the message. Neither the message nor its maps are modified.
+func (sdk *SendiosSdk) Send(ctx context.Context, msg EmailMessage) ([]byte, error) {
+	if err := msg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return sdk.sendEmail(ctx, msg)
+}
+
+func (sdk *SendiosSdk) sendEmail(ctx context.Context, msg EmailMessage) ([]byte, error) {
+	if err := sdk.checkSuppressed(ctx, msg.ProjectId, msg.Email, msg.TypeId); err != nil {
+		return nil, err
+	}
+
+	route, params, err := sdk.buildEmailSend(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return sdk.do(ctx, "SendEmail", http.MethodPost, sdk.apiV1Url(), internal.NewRoute(route), params, internal.WithProjectId(msg.ProjectId))
+}
+
+func (sdk *SendiosSdk) buildEmailSend(ctx context.Context, msg EmailMessage) (string, internal.EmailSend, error) {
+	jsonString, err := json.Marshal(msg.Data)
+	if err != nil {
+		return "", internal.EmailSend{}, fmt.Errorf("error while json marshaling: %w", err)
+	}
+
+	key, err := sdk.encryptionKeyOrDefault(ctx)
+	if err != nil {
+		return "", internal.EmailSend{}, err
+	}
+
+	encrypter, err := internal.MakeEncrypt(key)
+	if err != nil {
+		return "", internal.EmailSend{}, fmt.Errorf("error while encrypting: %w", err)
+	}
+
+	encrypt, err := encrypter.EncryptData(jsonString)
+	if err != nil {
+		return "", internal.EmailSend{}, fmt.Errorf("error data encrypting: %w", err)
+	}
+
+	user := make(map[string]string, len(msg.User)+1)
+	for key, value := range msg.User {
+		user[key] = value
+	}
+	user["email"] = msg.Email
+
+	params := internal.EmailSend{
+		TypeId:       msg.TypeId,
+		Category:     msg.Category,
+		ProjectId:    msg.ProjectId,
+		ClientId:     msg.ClientId,
+		User:         user,
+		Meta:         msg.Meta,
+		ValueEncrypt: internal.ValueEncrypt{TemplateData: encrypt},
+	}
+
+	route, err := getRoute(msg.Category)
+	if err != nil {
+		return "", internal.EmailSend{}, fmt.Errorf("error while getting route: %w", err)
+	}
+
+	return route, params, nil
+}
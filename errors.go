@@ -0,0 +1,78 @@
+package go_sdk
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/sendios/go-sdk/internal"
+)
+
+// APIError is returned for every response with a 4xx or 5xx status code.
+// Use errors.As to inspect it or one of the Is* helpers below.
+type APIError = internal.APIError
+
+// ErrRateLimitDeadline is returned when waiting for the client-side rate limiter would outlast the context deadline.
+var ErrRateLimitDeadline = internal.ErrRateLimitDeadline
+
+// ErrUserNotFound and ErrPushUserNotFound are returned by the methods which look up a user
+// before the actual request, such as IsUnsubByEmailAndProjectId or SendPushByEmailUserId,
+// when the user does not exist. The actual request is not made then.
+var (
+	ErrUserNotFound     = errors.New("email user not found")
+	ErrPushUserNotFound = errors.New("push user not found")
+)
+
+// ErrNoEncryptionKey is returned when sending an email without an encryption key configured
+// with WithEncryptionKey or EncryptionKeyEnv, unless WithLegacyEncryptionKey opted into the legacy one.
+var ErrNoEncryptionKey = errors.New("no encryption key configured")
+
+func IsNotFound(err error) bool {
+	return hasStatusCode(err, http.StatusNotFound)
+}
+
+func IsUnauthorized(err error) bool {
+	return hasStatusCode(err, http.StatusUnauthorized)
+}
+
+func IsForbidden(err error) bool {
+	return hasStatusCode(err, http.StatusForbidden)
+}
+
+func IsRateLimited(err error) bool {
+	return hasStatusCode(err, http.StatusTooManyRequests)
+}
+
+func IsServerError(err error) bool {
+	var apiError *APIError
+
+	return errors.As(err, &apiError) && apiError.StatusCode >= http.StatusInternalServerError
+}
+
+func hasStatusCode(err error, statusCode int) bool {
+	var apiError *APIError
+
+	return errors.As(err, &apiError) && apiError.StatusCode == statusCode
+}
+
+// notFoundError marks a failed lookup as one of the not found errors, while keeping the
+// *APIError of the lookup, if any, available to errors.As.
+type notFoundError struct {
+	err   error
+	cause error
+}
+
+func (e *notFoundError) Error() string {
+	if e.cause == nil {
+		return e.err.Error()
+	}
+
+	return e.err.Error() + ": " + e.cause.Error()
+}
+
+func (e *notFoundError) Is(target error) bool {
+	return target == e.err
+}
+
+func (e *notFoundError) Unwrap() error {
+	return e.cause
+}
@@ -0,0 +1,181 @@
+package internal
+
+import (
+	"container/list"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LRUCache is a size bounded cache whose entries expire after a ttl. It is safe for concurrent use.
+type LRUCache struct {
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	tags    map[string]map[string]struct{}
+	order   *list.List
+}
+
+type cacheEntry struct {
+	key       string
+	tag       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+// NewLRUCache returns a cache holding at most size entries, the least recently used being
+// evicted first. A ttl of 0 keeps entries until they are evicted.
+func NewLRUCache(size int, ttl time.Duration) *LRUCache {
+	if size < 1 {
+		size = 1
+	}
+
+	return &LRUCache{
+		size:    size,
+		ttl:     ttl,
+		entries: map[string]*list.Element{},
+		tags:    map[string]map[string]struct{}{},
+		order:   list.New(),
+	}
+}
+
+func (c *LRUCache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*cacheEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.remove(element)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+
+	return entry.value, true
+}
+
+func (c *LRUCache) Set(key string, value interface{}) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+// SetWithTTL stores the value with its own ttl, e.g. a shorter one for negative results.
+func (c *LRUCache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	c.SetTaggedWithTTL(key, value, "", ttl)
+}
+
+// SetTagged stores the value and indexes it by tag, so DeleteTag removes every entry of the tag
+// without going through the whole cache.
+func (c *LRUCache) SetTagged(key string, value interface{}, tag string) {
+	c.SetTaggedWithTTL(key, value, tag, c.ttl)
+}
+
+func (c *LRUCache) SetTaggedWithTTL(key string, value interface{}, tag string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*cacheEntry)
+		c.untag(entry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.tag(entry, tag)
+		c.order.MoveToFront(element)
+		return
+	}
+
+	entry := &cacheEntry{key: key, value: value, expiresAt: expiresAt}
+	c.entries[key] = c.order.PushFront(entry)
+	c.tag(entry, tag)
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *LRUCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+}
+
+// DeleteTag removes every entry stored with the tag.
+func (c *LRUCache) DeleteTag(tag string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.tags[tag] {
+		c.remove(c.entries[key])
+	}
+}
+
+// TaggedKeys returns the keys of the unexpired entries stored with the tag.
+func (c *LRUCache) TaggedKeys(tag string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	keys := make([]string, 0, len(c.tags[tag]))
+	for key := range c.tags[tag] {
+		entry := c.entries[key].Value.(*cacheEntry)
+		if entry.expiresAt.IsZero() || now.Before(entry.expiresAt) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *LRUCache) remove(element *list.Element) {
+	entry := element.Value.(*cacheEntry)
+	c.order.Remove(element)
+	delete(c.entries, entry.key)
+	c.untag(entry)
+}
+
+func (c *LRUCache) tag(entry *cacheEntry, tag string) {
+	if tag == "" {
+		return
+	}
+
+	keys, ok := c.tags[tag]
+	if !ok {
+		keys = map[string]struct{}{}
+		c.tags[tag] = keys
+	}
+	keys[entry.key] = struct{}{}
+	entry.tag = tag
+}
+
+func (c *LRUCache) untag(entry *cacheEntry) {
+	if entry.tag == "" {
+		return
+	}
+
+	keys := c.tags[entry.tag]
+	delete(keys, entry.key)
+	if len(keys) == 0 {
+		delete(c.tags, entry.tag)
+	}
+	entry.tag = ""
+}
@@ -1,20 +1,21 @@
 package internal
 
 import (
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/rand"
 	"encoding/base64"
 	"errors"
-	"strings"
+	"io"
 )
 
-var key = []byte("444500b1bd43b59f")
-
 type Encrypt struct {
 	block cipher.Block
 }
 
-func MakeEncrypt() (Encrypt, error) {
+// MakeEncrypt builds an AES cipher, the key must be 16, 24 or 32 bytes long.
+func MakeEncrypt(key []byte) (Encrypt, error) {
 	var block cipher.Block
 	var err error
 
@@ -33,13 +34,13 @@ func (encrypt Encrypt) Decrypt(encryptedData string) (string, error) {
 		return "", err
 	}
 
-	iv := cipherText[:aes.BlockSize]
-	cipherText = cipherText[aes.BlockSize:]
-
-	if len(cipherText) < aes.BlockSize {
+	if len(cipherText) < 2*aes.BlockSize {
 		return "", errors.New("ciphertext too short")
 	}
 
+	iv := cipherText[:aes.BlockSize]
+	cipherText = cipherText[aes.BlockSize:]
+
 	if len(cipherText)%aes.BlockSize != 0 {
 		return "", errors.New("ciphertext is not a multiple of the block size")
 	}
@@ -48,11 +49,52 @@ func (encrypt Encrypt) Decrypt(encryptedData string) (string, error) {
 
 	cbc.CryptBlocks(cipherText, cipherText)
 
-	return strings.TrimSpace(string(cipherText)), nil
+	plainText, err := pkcs7Unpad(cipherText)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plainText), nil
 }
 
+// EncryptData encrypts the data with AES-CBC and a random IV. The result is the base64
+// encoded IV followed by the PKCS#7 padded ciphertext, the format Decrypt expects.
 func (encrypt Encrypt) EncryptData(dataToEncrypt []byte) (string, error) {
-	encryptedString := base64.StdEncoding.EncodeToString(dataToEncrypt)
+	plainText := pkcs7Pad(dataToEncrypt, aes.BlockSize)
+	cipherText := make([]byte, aes.BlockSize+len(plainText))
+
+	iv := cipherText[:aes.BlockSize]
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return "", err
+	}
+
+	cbc := cipher.NewCBCEncrypter(encrypt.block, iv)
+	cbc.CryptBlocks(cipherText[aes.BlockSize:], plainText)
+
+	return base64.StdEncoding.EncodeToString(cipherText), nil
+}
+
+func pkcs7Pad(data []byte, blockSize int) []byte {
+	padding := blockSize - len(data)%blockSize
+
+	return append(append([]byte{}, data...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+}
+
+func pkcs7Unpad(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.New("invalid padding")
+	}
+
+	padding := int(data[len(data)-1])
+	if padding == 0 || padding > aes.BlockSize || padding > len(data) {
+		return nil, errors.New("invalid padding")
+	}
+
+	for _, b := range data[len(data)-padding:] {
+		if int(b) != padding {
+			return nil, errors.New("invalid padding")
+		}
+	}
 
-	return strings.TrimSpace(encryptedString), nil
+	return data[:len(data)-padding], nil
 }
@@ -1,6 +1,10 @@
 package internal
 
-import "github.com/joho/godotenv"
+import (
+	"os"
+
+	"github.com/joho/godotenv"
+)
 
 func GetEnvVariableByName(name string) (string, error) {
 	var myEnv map[string]string
@@ -12,3 +16,17 @@ func GetEnvVariableByName(name string) (string, error) {
 
 	return myEnv[name], nil
 }
+
+// LookupEnvVariable prefers the process environment and falls back to the .env file.
+func LookupEnvVariable(name string) string {
+	if value, ok := os.LookupEnv(name); ok {
+		return value
+	}
+
+	value, err := GetEnvVariableByName(name)
+	if err != nil {
+		return ""
+	}
+
+	return value
+}
@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+type APIError struct {
+	StatusCode int
+	MetaStatus string
+	Message    string
+	Method     string
+	Route      string
+	Body       []byte
+}
+
+func (e *APIError) Error() string {
+	message := e.Message
+	if message == "" {
+		message = http.StatusText(e.StatusCode)
+	}
+
+	if e.Method == "" {
+		return fmt.Sprintf("sendios api error: %d %s", e.StatusCode, message)
+	}
+
+	return fmt.Sprintf("sendios api error: %s %s: %d %s", e.Method, e.Route, e.StatusCode, message)
+}
+
+func NewAPIError(method string, route string, statusCode int, body []byte) *APIError {
+	apiError := &APIError{StatusCode: statusCode, Method: method, Route: route, Body: body}
+
+	var envelope errorEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return apiError
+	}
+
+	apiError.MetaStatus = envelope.Meta.Status
+	apiError.Message = firstNonEmpty(envelope.Error, envelope.Message)
+
+	var data errorData
+	if err := json.Unmarshal(envelope.Data, &data); err == nil {
+		apiError.Message = firstNonEmpty(data.Error, data.Message, apiError.Message)
+	}
+
+	return apiError
+}
+
+type errorEnvelope struct {
+	Meta    Meta            `json:"_meta"`
+	Data    json.RawMessage `json:"data"`
+	Error   string          `json:"error"`
+	Message string          `json:"message"`
+}
+
+type errorData struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,165 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Journal is an append-only file of json records, one per line. Every append is synced to
+// disk before it returns, so records survive a crash of the process.
+type Journal struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// OpenJournal opens or creates the journal and decodes every record into a json.RawMessage.
+// A torn last line, left by a crash in the middle of a write, is skipped.
+func OpenJournal(path string) (*Journal, []json.RawMessage, error) {
+	records, err := readJournal(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while opening journal: %w", err)
+	}
+
+	if err := terminateLastLine(file); err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	return &Journal{path: path, file: file}, records, nil
+}
+
+func (j *Journal) Append(record interface{}) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("error while marshaling journal record: %w", err)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("error while writing journal: %w", err)
+	}
+
+	return j.file.Sync()
+}
+
+// Rewrite atomically replaces the journal content with the given records.
+func (j *Journal) Rewrite(records []interface{}) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	tmp, err := ioutil.TempFile(filepath.Dir(j.path), filepath.Base(j.path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("error while creating journal: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := bufio.NewWriter(tmp)
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			tmp.Close()
+			return fmt.Errorf("error while marshaling journal record: %w", err)
+		}
+		writer.Write(append(line, '\n'))
+	}
+
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error while writing journal: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error while writing journal: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error while writing journal: %w", err)
+	}
+
+	// the new file is opened before it replaces the journal, so on any error the journal keeps
+	// appending to the current file
+	next, err := os.OpenFile(tmp.Name(), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("error while opening journal: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), j.path); err != nil {
+		next.Close()
+		return fmt.Errorf("error while replacing journal: %w", err)
+	}
+
+	previous := j.file
+	j.file = next
+	if err := previous.Close(); err != nil {
+		return fmt.Errorf("error while closing journal: %w", err)
+	}
+
+	return nil
+}
+
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.file.Close()
+}
+
+// terminateLastLine makes sure records appended after a torn line start on a line of their own.
+func terminateLastLine(file *os.File) error {
+	info, err := file.Stat()
+	if err != nil || info.Size() == 0 {
+		return err
+	}
+
+	last := make([]byte, 1)
+	if _, err := file.ReadAt(last, info.Size()-1); err != nil {
+		return fmt.Errorf("error while reading journal: %w", err)
+	}
+
+	if last[0] == '\n' {
+		return nil
+	}
+
+	_, err = file.Write([]byte{'\n'})
+
+	return err
+}
+
+func readJournal(path string) ([]json.RawMessage, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while reading journal: %w", err)
+	}
+	defer file.Close()
+
+	var records []json.RawMessage
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 || !json.Valid(line) {
+			continue
+		}
+		records = append(records, append(json.RawMessage{}, line...))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error while reading journal: %w", err)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// LogLevel uses the values of the log/slog levels, so slog.Level(level) converts it.
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = -4
+	LogLevelInfo  LogLevel = 0
+	LogLevelWarn  LogLevel = 4
+	LogLevelError LogLevel = 8
+)
+
+// Logger receives a record per request attempt. Args are alternating keys and values,
+// as with slog.Logger.Log.
+type Logger interface {
+	Log(ctx context.Context, level LogLevel, msg string, args ...interface{})
+}
+
+// logRequest logs what is about to be sent, at debug level as bodies may be large.
+func (r *Request) logRequest(ctx context.Context, req *http.Request, call *Call, attempt int) {
+	if r.Logger == nil {
+		return
+	}
+
+	r.Logger.Log(ctx, LogLevelDebug, "sendios request",
+		"method", call.Method,
+		"route", RedactRoute(call.Route),
+		"attempt", attempt,
+		"header", RedactHeader(req.Header),
+		"body", RedactJSON(call.Payload),
+	)
+}
+
+// logAttempt logs the outcome of an attempt: info when it succeeded, warn when it failed
+// and will be retried and error when the call failed.
+func (r *Request) logAttempt(ctx context.Context, call *Call, attempt int, response *Response, err error, latency time.Duration, retrying bool) {
+	if r.Logger == nil {
+		return
+	}
+
+	status := 0
+	if response != nil {
+		status = response.StatusCode
+	}
+	args := []interface{}{
+		"method", call.Method,
+		"route", RedactRoute(call.Route),
+		"status", status,
+		"latency", latency,
+		"attempt", attempt,
+	}
+
+	if err == nil {
+		r.Logger.Log(ctx, LogLevelInfo, "sendios request succeeded", args...)
+		return
+	}
+
+	args = append(args, "error", redactError(err))
+	if retrying {
+		r.Logger.Log(ctx, LogLevelWarn, "sendios request failed, retrying", args...)
+		return
+	}
+
+	r.Logger.Log(ctx, LogLevelError, "sendios request failed", args...)
+}
+
+// redactError describes err for the logs. The text of api and transport errors holds the
+// requested route, which may contain an encoded email, so it is rebuilt from the redacted parts.
+func redactError(err error) string {
+	var apiError *APIError
+	if errors.As(err, &apiError) {
+		if apiError.MetaStatus == "" {
+			return fmt.Sprintf("sendios api error: %d", apiError.StatusCode)
+		}
+		return fmt.Sprintf("sendios api error: %d %s", apiError.StatusCode, apiError.MetaStatus)
+	}
+
+	var urlError *url.Error
+	if errors.As(err, &urlError) {
+		return fmt.Sprintf("%s %s: %s", urlError.Op, RedactRoute(urlError.URL), RedactEmails(urlError.Err.Error()))
+	}
+
+	return RedactEmails(err.Error())
+}
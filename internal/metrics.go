@@ -0,0 +1,182 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the request latency histogram.
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+const unknownOperation = "unknown"
+
+// Metrics collects per operation request, error and retry counts and latency histograms.
+// It is safe for concurrent use and serves the metrics in the Prometheus text format.
+type Metrics struct {
+	buckets []float64
+
+	mu         sync.Mutex
+	operations map[string]*operationMetrics
+}
+
+type operationMetrics struct {
+	requests uint64
+	errors   map[string]uint64
+	retries  uint64
+	buckets  []uint64
+	sum      float64
+	count    uint64
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{buckets: DefaultLatencyBuckets, operations: map[string]*operationMetrics{}}
+}
+
+// observe records a finished call, retries included. A nil Metrics ignores it.
+func (m *Metrics) observe(operation string, response *Response, err error, latency time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	op := m.operation(operation)
+	op.requests++
+	if err != nil {
+		op.errors[statusClass(response)]++
+	}
+
+	seconds := latency.Seconds()
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			op.buckets[i]++
+		}
+	}
+	op.sum += seconds
+	op.count++
+}
+
+func (m *Metrics) retried(operation string) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.operation(operation).retries++
+}
+
+func (m *Metrics) operation(name string) *operationMetrics {
+	if name == "" {
+		name = unknownOperation
+	}
+
+	op, ok := m.operations[name]
+	if !ok {
+		op = &operationMetrics{errors: map[string]uint64{}, buckets: make([]uint64, len(m.buckets))}
+		m.operations[name] = op
+	}
+
+	return op
+}
+
+// statusClass is the label of a failed call: 4xx or 5xx, or no_response when the request
+// was not answered, e.g. because of a network error or a cancelled context.
+func statusClass(response *Response) string {
+	if response == nil || response.StatusCode == 0 {
+		return "no_response"
+	}
+
+	return fmt.Sprintf("%dxx", response.StatusCode/100)
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format. A nil Metrics,
+// as given by WithMetrics(nil), writes nothing.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.operations))
+	for name := range m.operations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+
+	b.WriteString("# HELP sendios_requests_total Requests made by the sendios sdk, retries excluded.\n")
+	b.WriteString("# TYPE sendios_requests_total counter\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "sendios_requests_total{operation=\"%s\"} %d\n", escapeLabel(name), m.operations[name].requests)
+	}
+
+	b.WriteString("# HELP sendios_request_errors_total Failed requests by status class.\n")
+	b.WriteString("# TYPE sendios_request_errors_total counter\n")
+	for _, name := range names {
+		counts := m.operations[name].errors
+		classes := make([]string, 0, len(counts))
+		for class := range counts {
+			classes = append(classes, class)
+		}
+		sort.Strings(classes)
+
+		for _, class := range classes {
+			fmt.Fprintf(&b, "sendios_request_errors_total{operation=\"%s\",class=\"%s\"} %d\n", escapeLabel(name), class, counts[class])
+		}
+	}
+
+	b.WriteString("# HELP sendios_request_retries_total Retried request attempts.\n")
+	b.WriteString("# TYPE sendios_request_retries_total counter\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "sendios_request_retries_total{operation=\"%s\"} %d\n", escapeLabel(name), m.operations[name].retries)
+	}
+
+	b.WriteString("# HELP sendios_request_duration_seconds Request latency, retries included.\n")
+	b.WriteString("# TYPE sendios_request_duration_seconds histogram\n")
+	for _, name := range names {
+		op := m.operations[name]
+		label := escapeLabel(name)
+		for i, bound := range m.buckets {
+			fmt.Fprintf(&b, "sendios_request_duration_seconds_bucket{operation=\"%s\",le=\"%s\"} %d\n", label, formatFloat(bound), op.buckets[i])
+		}
+		fmt.Fprintf(&b, "sendios_request_duration_seconds_bucket{operation=\"%s\",le=\"+Inf\"} %d\n", label, op.count)
+		fmt.Fprintf(&b, "sendios_request_duration_seconds_sum{operation=\"%s\"} %s\n", label, formatFloat(op.sum))
+		fmt.Fprintf(&b, "sendios_request_duration_seconds_count{operation=\"%s\"} %d\n", label, op.count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if m == nil {
+		return
+	}
+	_ = m.WritePrometheus(w)
+}
+
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+
+	return fmt.Sprintf("%g", value)
+}
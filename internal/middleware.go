@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Call describes a single sdk request as seen by middleware. Middleware may change any
+// field before calling the next handler, e.g. add a Header or rewrite the Payload.
+// Out is the destination the response body is decoded into, see Request.Do. Operation is the
+// name of the sdk method making the call, such as SendEmail, and RouteTemplate the route
+// without its ids, such as user/id/%d.
+type Call struct {
+	Operation     string
+	Method        string
+	BaseUrl       string
+	Route         string
+	RouteTemplate string
+	ProjectId     int
+	Query         url.Values
+	Header        http.Header
+	Payload       []byte
+	Out           interface{}
+}
+
+// Response is the outcome of a call. It is also returned along with an *APIError,
+// and is nil when no response was received.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+}
+
+// Handler performs a call and decodes the response body into call.Out. The innermost
+// handler sends the request, including rate limiting and retries.
+type Handler func(ctx context.Context, call *Call) (*Response, error)
+
+type Middleware func(next Handler) Handler
+
+// Chain wraps handler in middleware, the first middleware being the outermost one.
+func Chain(handler Handler, middleware []Middleware) Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+
+	return handler
+}
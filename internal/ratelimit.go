@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrRateLimitDeadline = errors.New("rate limiter wait would exceed context deadline")
+
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket returns nil, which does not limit anything, when requestsPerSecond is not positive.
+func NewTokenBucket(requestsPerSecond float64, burst int) *TokenBucket {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &TokenBucket{
+		rate:   requestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait takes a token from the bucket, blocking until one is available or the context is done.
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	b.refill(now)
+	b.tokens--
+
+	var wait time.Duration
+	if b.tokens < 0 {
+		wait = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && now.Add(wait).After(deadline) {
+		b.cancel()
+		return ErrRateLimitDeadline
+	}
+
+	if err := sleep(ctx, wait); err != nil {
+		b.cancel()
+		return err
+	}
+
+	return nil
+}
+
+func (b *TokenBucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+}
+
+func (b *TokenBucket) cancel() {
+	b.mu.Lock()
+	b.tokens++
+	b.mu.Unlock()
+}
+
+// RateLimiter combines a global bucket with optional buckets for individual routes. Routes are
+// route templates, as in Route, and a route bucket applies to the route itself and to every
+// route below it, e.g. "unsubtypes" matches "unsubtypes/%d".
+type RateLimiter struct {
+	Global *TokenBucket
+	Routes map[string]*TokenBucket
+}
+
+// Wait takes a token from the bucket of the route template and from the global bucket. The
+// route token is given back when waiting for the global one fails.
+func (l *RateLimiter) Wait(ctx context.Context, route string) error {
+	bucket := l.routeBucket(route)
+	if err := bucket.Wait(ctx); err != nil {
+		return err
+	}
+
+	if err := l.Global.Wait(ctx); err != nil {
+		if bucket != nil {
+			bucket.cancel()
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (l *RateLimiter) routeBucket(route string) *TokenBucket {
+	var matched string
+	var bucket *TokenBucket
+
+	for prefix, b := range l.Routes {
+		if route != prefix && !strings.HasPrefix(route, prefix+"/") {
+			continue
+		}
+		if len(prefix) > len(matched) {
+			matched, bucket = prefix, b
+		}
+	}
+
+	return bucket
+}
@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const RedactedValue = "REDACTED"
+
+// secretFields are json fields whose values are replaced with RedactedValue, such as the push
+// subscription keys sent by CreatePushUser.
+var secretFields = map[string]bool{"auth_token": true, "public_key": true}
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// decodedEmailPattern accepts any local part, as a decoded value is known to be a single token.
+var decodedEmailPattern = regexp.MustCompile(`^[^\s@]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}$`)
+
+// RedactEmails replaces every email address with a placeholder derived from its hash, so
+// equal addresses stay equal after redaction while the address itself is not revealed.
+func RedactEmails(s string) string {
+	return emailPattern.ReplaceAllStringFunc(s, RedactEmail)
+}
+
+func RedactEmail(email string) string {
+	sum := sha1.Sum([]byte(strings.ToLower(email)))
+
+	return fmt.Sprintf("redacted-%x@example.com", sum[:4])
+}
+
+// RedactRoute redacts plain emails as well as base64 encoded ones found in the segments of a
+// route such as unsub/admin/%d/email/%s. Segments are unescaped first, so emails escaped by
+// NewRoute are found too. Encoded emails are replaced by the encoded placeholder.
+func RedactRoute(route string) string {
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			unescaped = segment
+		}
+
+		if email, encoding, ok := decodeBase64Email(unescaped); ok {
+			segments[i] = EscapePathSegment(encoding.EncodeToString([]byte(RedactEmail(email))))
+			continue
+		}
+		if redacted := RedactEmails(unescaped); redacted != unescaped {
+			segments[i] = EscapePathSegment(redacted)
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func decodeBase64Email(segment string) (string, *base64.Encoding, bool) {
+	if len(segment) < 8 {
+		return "", nil, false
+	}
+
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		decoded, err := encoding.DecodeString(segment)
+		if err != nil {
+			continue
+		}
+		if email := string(decoded); decodedEmailPattern.MatchString(email) {
+			return email, encoding, true
+		}
+	}
+
+	return "", nil, false
+}
+
+// RedactHeader returns a copy of header without credentials and with emails redacted.
+func RedactHeader(header http.Header) http.Header {
+	redacted := make(http.Header, len(header))
+	for name, values := range header {
+		switch http.CanonicalHeaderKey(name) {
+		case "Authorization", "Cookie":
+			redacted[name] = []string{RedactedValue}
+		default:
+			for _, value := range values {
+				redacted[name] = append(redacted[name], RedactEmails(value))
+			}
+		}
+	}
+
+	return redacted
+}
+
+// RedactJSON redacts emails, plain or base64 encoded, in every string of a json document and the
+// values of secretFields.
+// Payloads which are not json only get their emails redacted.
+func RedactJSON(payload []byte) string {
+	var value interface{}
+	if err := json.Unmarshal(payload, &value); err != nil {
+		return RedactEmails(string(payload))
+	}
+
+	redacted, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return RedactEmails(string(payload))
+	}
+
+	return string(redacted)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if email, encoding, ok := decodeBase64Email(v); ok {
+			return encoding.EncodeToString([]byte(RedactEmail(email)))
+		}
+		return RedactEmails(v)
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			if secretFields[key] {
+				v[key] = RedactedValue
+				continue
+			}
+			v[key] = redactValue(item)
+		}
+	}
+
+	return value
+}
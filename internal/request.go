@@ -2,141 +2,251 @@ package internal
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
+	"time"
 )
 
 type Request struct {
-	Client *http.Client
-	Auth   *Auth
+	Client     *http.Client
+	Auth       *Auth
+	UserAgent  string
+	Retry      *RetryPolicy
+	Limiter    *RateLimiter
+	Middleware []Middleware
+	Logger     Logger
+	Metrics    *Metrics
+	Tracer     Tracer
 }
 
-func (r *Request) Post(url string, route string, data interface{}) ([]byte, error) {
-	postBody, err := json.Marshal(data)
-	body := bytes.NewBuffer(postBody)
+// CallOption customises a single call made with Do.
+type CallOption func(*Call)
 
-	req, err := http.NewRequest(http.MethodPost, url+route, body)
-	if err != nil {
-		return nil, fmt.Errorf("error while creating request: %s", err)
+// WithQuery adds query parameters to the request url.
+func WithQuery(query url.Values) CallOption {
+	return func(c *Call) {
+		for name, values := range query {
+			c.Query[name] = append(c.Query[name], values...)
+		}
 	}
+}
 
-	key := Sha1Encoder(r.Auth.AuthKey)
-	req.SetBasicAuth(r.Auth.ClientId, key)
+// WithOperation names the call for metrics and middleware.
+func WithOperation(operation string) CallOption {
+	return func(c *Call) {
+		c.Operation = operation
+	}
+}
 
-	response, err := r.Client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error while sending request: %s", err)
+func WithRouteTemplate(template string) CallOption {
+	return func(c *Call) {
+		c.RouteTemplate = template
 	}
+}
 
-	defer response.Body.Close()
+// WithProjectId sets the project the call is made for, reported to the Tracer.
+func WithProjectId(projectId int) CallOption {
+	return func(c *Call) {
+		c.ProjectId = projectId
+	}
+}
 
-	var result interface{}
-	err = json.NewDecoder(response.Body).Decode(&result)
-	if err != nil {
-		return nil, fmt.Errorf("error while decoding response data: %s", err)
+func WithHeader(name string, value string) CallOption {
+	return func(c *Call) {
+		c.Header.Add(name, value)
 	}
+}
 
-	encoded, err := json.Marshal(result)
-	if err != nil {
-		return nil, fmt.Errorf("error while unmarshaling response data: %s", err)
+// Do sends a request to baseUrl+route, baseUrl being the v1 or v3 api url. A non nil body is
+// sent as json. The response is decoded into out as it is read: a *[]byte receives the raw
+// compacted json, any other non nil value is decoded with encoding/json and a nil out discards it.
+func (r *Request) Do(ctx context.Context, method string, baseUrl string, route string, body interface{}, out interface{}, opts ...CallOption) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error while marshaling request data: %w", err)
+		}
 	}
 
-	return encoded, nil
+	call := &Call{
+		Method:  method,
+		BaseUrl: baseUrl,
+		Route:   route,
+		Query:   url.Values{},
+		Header:  http.Header{},
+		Payload: payload,
+		Out:     out,
+	}
+	for _, opt := range opts {
+		opt(call)
+	}
+	if call.RouteTemplate == "" {
+		call.RouteTemplate = route
+	}
+
+	_, err := Chain(r.execute, r.Middleware)(ctx, call)
+
+	return err
 }
 
-func (r *Request) Get(url string, route string) ([]byte, error) {
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s%s", url, route), nil)
-	if err != nil {
-		return nil, fmt.Errorf("error while creating request: %s", err)
+// execute sends the request, retrying it according to the retry policy. POST requests are
+// only retried when the context carries an idempotency key, as they are not safe to repeat otherwise.
+func (r *Request) execute(ctx context.Context, call *Call) (*Response, error) {
+	policy := r.Retry
+	if policy == nil {
+		policy = &RetryPolicy{MaxAttempts: 1}
 	}
 
-	key := Sha1Encoder(r.Auth.AuthKey)
-	req.SetBasicAuth(r.Auth.ClientId, key)
+	retryable := call.Method != http.MethodPost || IdempotencyKeyFromContext(ctx) != ""
 
-	response, err := r.Client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error while sending request: %s", err)
-	}
+	ctx, span := r.startSpan(ctx, call)
+	start := time.Now()
+	response, err := r.retry(ctx, call, policy, retryable)
+	r.Metrics.observe(call.Operation, response, err, time.Since(start))
+	endSpan(span, response, err)
 
-	defer response.Body.Close()
+	return response, err
+}
 
-	var result interface{}
-	err = json.NewDecoder(response.Body).Decode(&result)
-	if err != nil {
-		return nil, fmt.Errorf("error while decoding response data: %s", err)
+func (r *Request) retry(ctx context.Context, call *Call, policy *RetryPolicy, retryable bool) (*Response, error) {
+	for attempt := 1; ; attempt++ {
+		if r.Limiter != nil {
+			if err := r.Limiter.Wait(ctx, call.RouteTemplate); err != nil {
+				return nil, fmt.Errorf("error while waiting for rate limiter: %w", err)
+			}
+		}
+
+		start := time.Now()
+		response, err := r.attempt(ctx, call, attempt)
+		retrying := err != nil && retryable && attempt < policy.MaxAttempts && policy.ShouldRetry(ctx, err)
+		r.logAttempt(ctx, call, attempt, response, err, time.Since(start), retrying)
+
+		if err == nil {
+			return response, nil
+		}
+
+		if !retrying {
+			return response, err
+		}
+		r.Metrics.retried(call.Operation)
+
+		var retryAfter time.Duration
+		if response != nil {
+			retryAfter = ParseRetryAfter(response.Header.Get("Retry-After"), time.Now())
+		}
+
+		if err := sleep(ctx, policy.Backoff(attempt, retryAfter)); err != nil {
+			return response, fmt.Errorf("error while waiting for retry: %w", err)
+		}
 	}
+}
 
-	encoded, err := json.Marshal(result)
-	if err != nil {
-		return nil, fmt.Errorf("error while unmarshaling response data: %s", err)
+func (r *Request) attempt(ctx context.Context, call *Call, attempt int) (*Response, error) {
+	var body io.Reader
+	if call.Payload != nil {
+		body = bytes.NewReader(call.Payload)
 	}
 
-	return encoded, nil
-}
-
-func (r *Request) Delete(url string, route string, data interface{}) ([]byte, error) {
-	postBody, err := json.Marshal(data)
-	body := bytes.NewBuffer(postBody)
+	target := call.BaseUrl + call.Route
+	if len(call.Query) > 0 {
+		target += "?" + call.Query.Encode()
+	}
 
-	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s%s", url, route), body)
+	req, err := http.NewRequestWithContext(ctx, call.Method, target, body)
 	if err != nil {
-		return nil, fmt.Errorf("error while creating request: %s", err)
+		return nil, fmt.Errorf("error while creating request: %w", err)
+	}
+
+	for name, values := range call.Header {
+		req.Header[name] = append([]string(nil), values...)
 	}
 
 	key := Sha1Encoder(r.Auth.AuthKey)
 	req.SetBasicAuth(r.Auth.ClientId, key)
+	if r.UserAgent != "" {
+		req.Header.Set("User-Agent", r.UserAgent)
+	}
+	if idempotencyKey := IdempotencyKeyFromContext(ctx); idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	if traceparent := TraceparentFromContext(ctx); traceparent != "" && req.Header.Get("traceparent") == "" {
+		req.Header.Set("traceparent", traceparent)
+	}
+	r.logRequest(ctx, req, call, attempt)
 
 	response, err := r.Client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error while sending request: %s", err)
+		return nil, &sendError{err: err}
 	}
 
 	defer response.Body.Close()
 
-	var result interface{}
-	err = json.NewDecoder(response.Body).Decode(&result)
-	if err != nil {
-		return nil, fmt.Errorf("error while decoding response data: %s", err)
-	}
-
-	encoded, err := json.Marshal(result)
-	if err != nil {
-		return nil, fmt.Errorf("error while unmarshaling response data: %s", err)
-	}
+	result := &Response{StatusCode: response.StatusCode, Header: response.Header}
 
-	return encoded, nil
+	return result, decodeResponse(call, response)
 }
 
-func (r *Request) Put(url string, route string, data interface{}) ([]byte, error) {
-	putBody, err := json.Marshal(data)
-	body := bytes.NewBuffer(putBody)
+func decodeResponse(call *Call, response *http.Response) error {
+	// the rest of the body is drained so the connection can be reused
+	defer io.Copy(ioutil.Discard, response.Body)
 
-	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s%s", url, route), body)
-	if err != nil {
-		return nil, fmt.Errorf("error while creating request: %s", err)
+	if response.StatusCode >= http.StatusBadRequest {
+		raw, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			return &sendError{err: fmt.Errorf("error while reading response data: %w", err)}
+		}
+
+		return NewAPIError(call.Method, call.Route, response.StatusCode, raw)
 	}
 
-	key := Sha1Encoder(r.Auth.AuthKey)
-	req.SetBasicAuth(r.Auth.ClientId, key)
+	switch out := call.Out.(type) {
+	case nil:
+		return nil
+	case *[]byte:
+		raw, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			return &sendError{err: fmt.Errorf("error while reading response data: %w", err)}
+		}
 
-	response, err := r.Client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error while sending request: %s", err)
-	}
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, raw); err != nil {
+			return fmt.Errorf("error while decoding response data: %w", err)
+		}
+		*out = compacted.Bytes()
 
-	defer response.Body.Close()
+		return nil
+	default:
+		if err := json.NewDecoder(response.Body).Decode(out); err != nil {
+			return fmt.Errorf("error while decoding response data: %w", err)
+		}
 
-	var result interface{}
-	err = json.NewDecoder(response.Body).Decode(&result)
-	if err != nil {
-		return nil, fmt.Errorf("error while decoding response data: %s", err)
+		return nil
 	}
+}
 
-	encoded, err := json.Marshal(result)
-	if err != nil {
-		return nil, fmt.Errorf("error while unmarshaling response data: %s", err)
-	}
+// sendError marks transport failures, which are always worth retrying.
+type sendError struct {
+	err error
+}
+
+func (e *sendError) Error() string {
+	return fmt.Sprintf("error while sending request: %s", e.err)
+}
+
+func (e *sendError) Unwrap() error {
+	return e.err
+}
+
+func isSendError(err error) bool {
+	var target *sendError
 
-	return encoded, nil
+	return errors.As(err, &target)
 }
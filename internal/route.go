@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Route is an api route along with the template it was built from, e.g. user/id/%d,
+// which identifies the endpoint without the ids of the request.
+type Route struct {
+	Template string
+	Path     string
+}
+
+// NewRoute formats the template with args, escaping string args so that each of them stays
+// a single path segment, whatever characters an email, base64 or push hash contains.
+func NewRoute(template string, args ...interface{}) Route {
+	escaped := make([]interface{}, len(args))
+	for i, arg := range args {
+		if s, ok := arg.(string); ok {
+			arg = EscapePathSegment(s)
+		}
+		escaped[i] = arg
+	}
+
+	return Route{Template: template, Path: fmt.Sprintf(template, escaped...)}
+}
+
+// EscapePathSegment escapes s for use as a path segment. Unlike url.PathEscape it also
+// escapes +, which some servers decode to a space.
+func EscapePathSegment(s string) string {
+	return strings.Replace(url.PathEscape(s), "+", "%2B", -1)
+}
@@ -85,36 +85,13 @@ type WebpushUserCreate struct {
 	Meta   map[string]string `json:"meta"`
 }
 
-type ResponseEmailData struct {
-	Data EmailData `json:"data"`
-}
-
-type ResponsePushData struct {
-	Data PushData `json:"data"`
-}
-
-type EmailData struct {
-	User EmailUser `json:"user"`
-}
-
-type PushData struct {
-	PushUser PushUser `json:"result"`
-}
-
-type EmailUser struct {
-	Id        int    `json:"id"`
-	Email     string `json:"email"`
-	ProjectId int    `json:"project_id"`
-	Name      string `json:"name"`
-}
-
-type PushUser struct {
-	Id        int `json:"id"`
-	UserId    int `json:"user_id"`
-	ProjectId int `json:"project_id"`
-}
-
 type Auth struct {
 	ClientId string
 	AuthKey  string
 }
+
+type Meta struct {
+	Count  int    `json:"count"`
+	Status string `json:"status"`
+	Time   int    `json:"time"`
+}
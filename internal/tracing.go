@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"context"
+	"regexp"
+)
+
+// SpanAttributes describe a call to the api. ProjectId is 0 for calls not tied to a project.
+type SpanAttributes struct {
+	Operation     string
+	Method        string
+	RouteTemplate string
+	ProjectId     int
+}
+
+// Tracer starts a span for every call made by the sdk, retries included. The context returned
+// by Start is used for the call, so a tracer can put the traceparent of its span in it.
+type Tracer interface {
+	Start(ctx context.Context, attrs SpanAttributes) (context.Context, Span)
+}
+
+// Span is ended with the status code of the last response, 0 when none was received,
+// and the error of the call.
+type Span interface {
+	End(statusCode int, err error)
+}
+
+type traceparentKey struct{}
+
+var traceparentPattern = regexp.MustCompile(`^[0-9a-f]{2}-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$`)
+
+// ContextWithTraceparent attaches a W3C traceparent, such as the one received by an http
+// server, to the context. Invalid values are ignored.
+func ContextWithTraceparent(ctx context.Context, traceparent string) context.Context {
+	if !traceparentPattern.MatchString(traceparent) {
+		return ctx
+	}
+
+	return context.WithValue(ctx, traceparentKey{}, traceparent)
+}
+
+func TraceparentFromContext(ctx context.Context) string {
+	traceparent, _ := ctx.Value(traceparentKey{}).(string)
+
+	return traceparent
+}
+
+func (r *Request) startSpan(ctx context.Context, call *Call) (context.Context, Span) {
+	if r.Tracer == nil {
+		return ctx, nil
+	}
+
+	return r.Tracer.Start(ctx, SpanAttributes{
+		Operation:     call.Operation,
+		Method:        call.Method,
+		RouteTemplate: call.RouteTemplate,
+		ProjectId:     call.ProjectId,
+	})
+}
+
+func endSpan(span Span, response *Response, err error) {
+	if span == nil {
+		return
+	}
+
+	statusCode := 0
+	if response != nil {
+		statusCode = response.StatusCode
+	}
+	span.End(statusCode, err)
+}
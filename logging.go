@@ -0,0 +1,33 @@
+package go_sdk
+
+import (
+	"context"
+
+	"github.com/sendios/go-sdk/internal"
+)
+
+// LogLevel has the values of the log/slog levels.
+type LogLevel = internal.LogLevel
+
+const (
+	LogLevelDebug = internal.LogLevelDebug
+	LogLevelInfo  = internal.LogLevelInfo
+	LogLevelWarn  = internal.LogLevelWarn
+	LogLevelError = internal.LogLevelError
+)
+
+// Logger receives a record for every request attempt with the method, route, status, latency
+// and attempt number, and a debug record with the headers and body sent. Emails, credentials
+// and push subscription keys are redacted before they reach the logger.
+type Logger = internal.Logger
+
+// LoggerFunc adapts a function to Logger. With log/slog:
+//
+//	sendios.WithLogger(sendios.LoggerFunc(func(ctx context.Context, level sendios.LogLevel, msg string, args ...interface{}) {
+//		logger.Log(ctx, slog.Level(level), msg, args...)
+//	}))
+type LoggerFunc func(ctx context.Context, level LogLevel, msg string, args ...interface{})
+
+func (f LoggerFunc) Log(ctx context.Context, level LogLevel, msg string, args ...interface{}) {
+	f(ctx, level, msg, args...)
+}
@@ -0,0 +1,21 @@
+package go_sdk
+
+import "github.com/sendios/go-sdk/internal"
+
+// Metrics counts the requests, errors by status class and retries of the sdk and keeps a
+// latency histogram, all labelled by operation, the name of the sdk method such as SendEmail.
+// It implements http.Handler, serving the metrics in the Prometheus text format:
+//
+//	http.Handle("/metrics", sdk.Metrics())
+type Metrics = internal.Metrics
+
+func NewMetrics() *Metrics {
+
+	return internal.NewMetrics()
+}
+
+// Metrics returns the collector the sdk updates, see WithMetrics.
+func (sdk *SendiosSdk) Metrics() *Metrics {
+
+	return sdk.Request.Metrics
+}
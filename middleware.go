@@ -0,0 +1,27 @@
+package go_sdk
+
+import "github.com/sendios/go-sdk/internal"
+
+// Call is the request passed through middleware: method, base url, route, query, extra headers
+// and json payload. The base url is one of the v1 and v3 urls, see WithApiV1BaseUrl. The response
+// body is decoded into Out, which is a *[]byte for the methods returning the raw response.
+type Call = internal.Call
+
+// CallResponse holds the status code and headers of the response to a Call.
+type CallResponse = internal.Response
+
+// Handler performs a Call. It returns the response, if one was received, and the error of the request.
+type Handler = internal.Handler
+
+// Middleware wraps every request made by the sdk, retries and rate limiting included, e.g.
+//
+//	func auditing(next sendios.Handler) sendios.Handler {
+//		return func(ctx context.Context, call *sendios.Call) (*sendios.CallResponse, error) {
+//			call.Header.Set("X-Request-Source", "billing")
+//			response, err := next(ctx, call)
+//			log.Printf("%s %s: %v", call.Method, call.Route, err)
+//
+//			return response, err
+//		}
+//	}
+type Middleware = internal.Middleware
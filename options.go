@@ -0,0 +1,254 @@
+package go_sdk
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sendios/go-sdk/internal"
+)
+
+const DefaultTimeout = time.Second * 10
+
+type Option func(*config)
+
+type config struct {
+	apiV1     string
+	apiV3     string
+	client    *http.Client
+	transport http.RoundTripper
+	timeout   time.Duration
+	userAgent string
+	retry     RetryPolicy
+	limiter   *internal.RateLimiter
+
+	encryptionKey       []byte
+	legacyEncryptionKey bool
+	middleware          []Middleware
+	logger              Logger
+	metrics             *Metrics
+	tracer              Tracer
+	userIds             UserIdCache
+
+	suppressions      *SuppressionList
+	checkSuppressions bool
+
+	pushUserCacheSize   int
+	pushUserCacheTTL    time.Duration
+	pushUserNegativeTTL time.Duration
+}
+
+// WithApiV1BaseUrl overrides the base url used for v1 endpoints, e.g. a staging host or an httptest server.
+func WithApiV1BaseUrl(url string) Option {
+	return func(c *config) {
+		c.apiV1 = normalizeBaseUrl(url)
+	}
+}
+
+// WithApiV3BaseUrl overrides the base url used for v3 endpoints.
+func WithApiV3BaseUrl(url string) Option {
+	return func(c *config) {
+		c.apiV3 = normalizeBaseUrl(url)
+	}
+}
+
+// WithHttpClient makes the sdk send requests through the given client. The client is copied
+// when WithTimeout or WithTransport are also supplied, so the caller's value is never modified.
+func WithHttpClient(client *http.Client) Option {
+	return func(c *config) {
+		c.client = client
+	}
+}
+
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *config) {
+		c.transport = transport
+	}
+}
+
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.timeout = timeout
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(c *config) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy. Use RetryPolicy{MaxAttempts: 1} to disable retries.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *config) {
+		c.retry = policy
+	}
+}
+
+// WithRateLimit limits the rate of all requests made by the sdk, retries included.
+// Calls block until a token is available or their context is done. A requestsPerSecond of 0
+// or less means no limit.
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(c *config) {
+		c.rateLimiter().Global = internal.NewTokenBucket(requestsPerSecond, burst)
+	}
+}
+
+// WithRouteRateLimit adds a limit for a route, applied on top of the global one. The route is
+// matched against the route template of the call, with %d and %s in place of the parameters,
+// e.g. "webpush/send" or "unsub/admin/%d/email/%s", and also limits the routes below it, so
+// "unsubtypes" covers "unsubtypes/%d" and "unsubtypes/nodiff/%d". A requestsPerSecond of 0 or
+// less removes the limit of the route.
+func WithRouteRateLimit(route string, requestsPerSecond float64, burst int) Option {
+	return func(c *config) {
+		route = strings.Trim(route, "/")
+		if bucket := internal.NewTokenBucket(requestsPerSecond, burst); bucket != nil {
+			c.rateLimiter().Routes[route] = bucket
+		} else {
+			delete(c.rateLimiter().Routes, route)
+		}
+	}
+}
+
+// WithEncryptionKey sets the AES key, 16, 24 or 32 bytes long, used to encrypt SendEmail template data.
+// It takes precedence over the EncryptionKeyEnv environment variable. Without either, SendEmail
+// returns ErrNoEncryptionKey.
+func WithEncryptionKey(key string) Option {
+	return func(c *config) {
+		c.encryptionKey = []byte(key)
+	}
+}
+
+// WithLegacyEncryptionKey lets the sdk fall back to the key of the first sdk releases when no key is
+// configured. That key is publicly known, so the template data is not protected; the fallback is
+// deprecated and logged as a warning. It only exists so setups relying on it can migrate.
+func WithLegacyEncryptionKey() Option {
+	return func(c *config) {
+		c.legacyEncryptionKey = true
+	}
+}
+
+// WithMiddleware appends middleware to the chain wrapping every request. The middleware
+// given first is the outermost one and sees the call before any other.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(c *config) {
+		c.middleware = append(c.middleware, middleware...)
+	}
+}
+
+// WithLogger logs every request attempt, see Logger.
+func WithLogger(logger Logger) Option {
+	return func(c *config) {
+		c.logger = logger
+	}
+}
+
+// WithMetrics makes the sdk update the given collector, e.g. to share one between several
+// sdk instances. By default every sdk has its own, see SendiosSdk.Metrics.
+func WithMetrics(metrics *Metrics) Option {
+	return func(c *config) {
+		c.metrics = metrics
+	}
+}
+
+// WithTracer starts a span around every call of the sdk, retries included.
+func WithTracer(tracer Tracer) Option {
+	return func(c *config) {
+		c.tracer = tracer
+	}
+}
+
+// WithUserIdCache replaces the default NewUserIdLRUCache, e.g. with a cache shared between
+// instances. A nil cache disables caching.
+func WithUserIdCache(cache UserIdCache) Option {
+	return func(c *config) {
+		c.userIds = cache
+	}
+}
+
+// WithPushUserCache configures the cache of the push users resolved by the push methods
+// taking an email user id or a project id and hash. Lookups which found no push user are
+// cached for negativeTTL, 0 disables negative caching. A size of 0 disables the cache.
+func WithPushUserCache(size int, ttl time.Duration, negativeTTL time.Duration) Option {
+	return func(c *config) {
+		c.pushUserCacheSize = size
+		c.pushUserCacheTTL = ttl
+		c.pushUserNegativeTTL = negativeTTL
+	}
+}
+
+// WithSuppressionList makes the sdk record the unsubscribes and resubscribes it sends, and
+// those listed by UnsubFeed, in the list. Sending only consults it with WithSuppressionCheck.
+// A failure to save the list does not fail the call which changed the subscription, it is
+// logged, see WithLogger.
+func WithSuppressionList(list *SuppressionList) Option {
+	return func(c *config) {
+		c.suppressions = list
+	}
+}
+
+// WithSuppressionCheck makes SendEmail and Send return ErrSuppressed, without a request, for
+// users the suppression list knows to be unsubscribed from the email type.
+func WithSuppressionCheck() Option {
+	return func(c *config) {
+		c.checkSuppressions = true
+	}
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		apiV1:               ApiV1,
+		apiV3:               ApiV3,
+		retry:               DefaultRetryPolicy(),
+		metrics:             NewMetrics(),
+		userIds:             NewUserIdLRUCache(DefaultUserIdCacheSize, DefaultUserIdCacheTTL),
+		pushUserCacheSize:   DefaultPushUserCacheSize,
+		pushUserCacheTTL:    DefaultPushUserCacheTTL,
+		pushUserNegativeTTL: DefaultPushUserNegativeTTL,
+		encryptionKey:       []byte(internal.LookupEnvVariable(EncryptionKeyEnv)),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+func (cfg *config) rateLimiter() *internal.RateLimiter {
+	if cfg.limiter == nil {
+		cfg.limiter = &internal.RateLimiter{Routes: map[string]*internal.TokenBucket{}}
+	}
+
+	return cfg.limiter
+}
+
+func (cfg *config) httpClient() *http.Client {
+	var client *http.Client
+
+	if cfg.client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	} else if cfg.transport != nil || cfg.timeout != 0 {
+		c := *cfg.client
+		client = &c
+	} else {
+		client = cfg.client
+	}
+
+	if cfg.transport != nil {
+		client.Transport = cfg.transport
+	}
+
+	if cfg.timeout != 0 {
+		client.Timeout = cfg.timeout
+	}
+
+	return client
+}
+
+func normalizeBaseUrl(url string) string {
+	if strings.HasSuffix(url, "/") {
+		return url
+	}
+
+	return url + "/"
+}
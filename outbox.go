@@ -0,0 +1,486 @@
+package go_sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sendios/go-sdk/internal"
+)
+
+const (
+	OutboxKindEmail = "email"
+	OutboxKindPush  = "push"
+
+	DefaultOutboxMaxAttempts = 5
+	DefaultOutboxRetention   = 7 * 24 * time.Hour
+
+	outboxJournalFile    = "outbox.journal"
+	outboxDeadLetterFile = "dead_letters.journal"
+
+	// outboxCompactThreshold is the number of records appended to the journal after which
+	// Flush compacts it.
+	outboxCompactThreshold = 1000
+)
+
+const (
+	outboxOpEnqueue = "enqueue"
+	outboxOpFailure = "failure"
+	outboxOpDone    = "done"
+	outboxOpDead    = "dead"
+)
+
+// outboxOperations labels the metrics of delivered entries by kind.
+var outboxOperations = map[string]string{OutboxKindEmail: "SendEmail", OutboxKindPush: "SendPush"}
+
+var (
+	ErrEmptyIdempotencyKey = errors.New("idempotency key is empty")
+	ErrInvalidPushMessage  = errors.New("invalid push message")
+)
+
+// PushMessage describes a web push for the outbox. Either PushUserId or ProjectId must be set,
+// the latter sends the push to every push user of the project.
+type PushMessage struct {
+	PushUserId int
+	ProjectId  int
+	TypeId     int
+	Title      string
+	Text       string
+	Url        string
+	IconUrl    string
+	ImageUrl   string
+	Meta       map[string]string
+}
+
+func (m PushMessage) Validate() error {
+	if m.PushUserId <= 0 && m.ProjectId <= 0 {
+		return fmt.Errorf("%w: either push user id or project id must be positive", ErrInvalidPushMessage)
+	}
+
+	if strings.TrimSpace(m.Title) == "" {
+		return fmt.Errorf("%w: title is empty", ErrInvalidPushMessage)
+	}
+
+	return nil
+}
+
+type OutboxOptions struct {
+	// Dir holds the journal and the dead letter file, it is created if missing.
+	Dir string
+	// MaxAttempts is the number of failed deliveries after which a message is moved to the dead
+	// letter file, DefaultOutboxMaxAttempts when not positive.
+	MaxAttempts int
+	// Retention is how long the keys of delivered and dead messages are remembered, so enqueuing
+	// them again is a no-op, DefaultOutboxRetention when not positive.
+	Retention time.Duration
+}
+
+// OutboxEntry is a message waiting for delivery. Payload is the exact request body, template
+// data is encrypted at enqueue time so replaying does not depend on the original message.
+type OutboxEntry struct {
+	Key       string          `json:"key"`
+	Kind      string          `json:"kind"`
+	Route     string          `json:"route"`
+	Payload   json.RawMessage `json:"payload"`
+	Attempts  int             `json:"attempts"`
+	LastError string          `json:"last_error,omitempty"`
+}
+
+type outboxRecord struct {
+	Op    string       `json:"op"`
+	Key   string       `json:"key"`
+	Entry *OutboxEntry `json:"entry,omitempty"`
+	Error string       `json:"error,omitempty"`
+	Time  time.Time    `json:"time"`
+}
+
+type outboxKey struct {
+	op   string
+	time time.Time
+}
+
+type deadLetter struct {
+	OutboxEntry
+	Time time.Time `json:"time"`
+}
+
+// Outbox persists emails and pushes to a local journal before they are sent, so a message
+// accepted by Enqueue* survives a crash and is delivered by a later Flush, also after restart.
+// Every message is sent with its key as idempotency key, which makes redelivery safe.
+type Outbox struct {
+	// appended counts the records written since the last compaction, first for 64-bit alignment.
+	appended int64
+
+	sdk         *SendiosSdk
+	maxAttempts int
+	retention   time.Duration
+	journal     *internal.Journal
+	deadLetters *internal.Journal
+
+	flushMu sync.Mutex
+	mu      sync.Mutex
+	pending []*OutboxEntry
+	seen    map[string]outboxKey
+}
+
+// NewOutbox opens the outbox in opts.Dir and restores the messages left pending by a previous run.
+func (sdk *SendiosSdk) NewOutbox(opts OutboxOptions) (*Outbox, error) {
+	if err := os.MkdirAll(opts.Dir, 0700); err != nil {
+		return nil, fmt.Errorf("error while creating outbox directory: %w", err)
+	}
+
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultOutboxMaxAttempts
+	}
+	retention := opts.Retention
+	if retention <= 0 {
+		retention = DefaultOutboxRetention
+	}
+
+	journal, records, err := internal.OpenJournal(filepath.Join(opts.Dir, outboxJournalFile))
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetters, _, err := internal.OpenJournal(filepath.Join(opts.Dir, outboxDeadLetterFile))
+	if err != nil {
+		journal.Close()
+		return nil, err
+	}
+
+	o := &Outbox{
+		sdk:         sdk,
+		maxAttempts: maxAttempts,
+		retention:   retention,
+		journal:     journal,
+		deadLetters: deadLetters,
+		seen:        map[string]outboxKey{},
+	}
+
+	if err := o.restore(records); err != nil {
+		o.Close()
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// EnqueueEmail returns ErrSuppressed like Send when the suppression check is enabled; users
+// suppressed after the message was enqueued are caught by Flush.
+func (o *Outbox) EnqueueEmail(key string, msg EmailMessage) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+	if err := o.sdk.checkSuppressed(context.Background(), msg.ProjectId, msg.Email, msg.TypeId); err != nil {
+		return err
+	}
+
+	route, params, err := o.sdk.buildEmailSend(context.Background(), msg)
+	if err != nil {
+		return err
+	}
+
+	return o.enqueue(key, OutboxKindEmail, route, params)
+}
+
+func (o *Outbox) EnqueuePush(key string, msg PushMessage) error {
+	if err := msg.Validate(); err != nil {
+		return err
+	}
+
+	params := internal.WebpushSend{
+		PushUserId: msg.PushUserId,
+		ProjectId:  msg.ProjectId,
+		TypeId:     msg.TypeId,
+		Title:      msg.Title,
+		Text:       msg.Text,
+		Url:        msg.Url,
+		Icon:       msg.IconUrl,
+		ImageUrl:   msg.ImageUrl,
+		Meta:       msg.Meta,
+	}
+
+	return o.enqueue(key, OutboxKindPush, "webpush/send", params)
+}
+
+// Pending returns a copy of the messages waiting for delivery, in enqueue order.
+func (o *Outbox) Pending() []OutboxEntry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	entries := make([]OutboxEntry, 0, len(o.pending))
+	for _, entry := range o.pending {
+		entries = append(entries, *entry)
+	}
+
+	return entries
+}
+
+// Flush tries to deliver every pending message once. Delivery failures are recorded in the
+// journal rather than returned; the error is only set when ctx is done or the journal fails.
+// Emails to users suppressed since they were enqueued are moved to the dead letter file.
+func (o *Outbox) Flush(ctx context.Context) error {
+	o.flushMu.Lock()
+	defer o.flushMu.Unlock()
+
+	for _, entry := range o.Pending() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := o.checkSuppressed(ctx, entry); err != nil {
+			entry.LastError = err.Error()
+			if err := o.bury(entry); err != nil {
+				return err
+			}
+			continue
+		}
+
+		operation := internal.WithOperation(outboxOperations[entry.Kind])
+		err := o.sdk.Request.Do(ContextWithIdempotencyKey(ctx, entry.Key), http.MethodPost, o.sdk.apiV1Url(), entry.Route, entry.Payload, nil, operation)
+		if err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err := o.complete(entry, err); err != nil {
+			return err
+		}
+	}
+
+	if atomic.LoadInt64(&o.appended) >= outboxCompactThreshold {
+		return o.compact()
+	}
+
+	return nil
+}
+
+// Run flushes the outbox every interval until ctx is done.
+func (o *Outbox) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := o.Flush(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (o *Outbox) Close() error {
+	o.flushMu.Lock()
+	defer o.flushMu.Unlock()
+
+	err := o.journal.Close()
+	if deadErr := o.deadLetters.Close(); err == nil {
+		err = deadErr
+	}
+
+	return err
+}
+
+func (o *Outbox) enqueue(key string, kind string, route string, params interface{}) error {
+	if key == "" {
+		return ErrEmptyIdempotencyKey
+	}
+
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("error while marshaling outbox payload: %w", err)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, ok := o.seen[key]; ok {
+		return nil
+	}
+
+	now := time.Now()
+	entry := &OutboxEntry{Key: key, Kind: kind, Route: route, Payload: payload}
+	if err := o.append(outboxRecord{Op: outboxOpEnqueue, Key: key, Entry: entry, Time: now}); err != nil {
+		return err
+	}
+
+	o.pending = append(o.pending, entry)
+	o.seen[key] = outboxKey{op: outboxOpEnqueue, time: now}
+
+	return nil
+}
+
+func (o *Outbox) complete(entry OutboxEntry, sendErr error) error {
+	now := time.Now()
+
+	if sendErr == nil {
+		if err := o.append(outboxRecord{Op: outboxOpDone, Key: entry.Key, Time: now}); err != nil {
+			return err
+		}
+
+		o.remove(entry.Key, outboxOpDone, now)
+		return nil
+	}
+
+	entry.Attempts++
+	entry.LastError = sendErr.Error()
+
+	if entry.Attempts < o.maxAttempts {
+		if err := o.append(outboxRecord{Op: outboxOpFailure, Key: entry.Key, Error: entry.LastError, Time: now}); err != nil {
+			return err
+		}
+
+		o.update(entry)
+		return nil
+	}
+
+	return o.bury(entry)
+}
+
+// bury moves the entry to the dead letter file.
+func (o *Outbox) bury(entry OutboxEntry) error {
+	now := time.Now()
+
+	if err := o.deadLetters.Append(deadLetter{OutboxEntry: entry, Time: now}); err != nil {
+		return err
+	}
+	if err := o.append(outboxRecord{Op: outboxOpDead, Key: entry.Key, Error: entry.LastError, Time: now}); err != nil {
+		return err
+	}
+
+	o.remove(entry.Key, outboxOpDead, now)
+	return nil
+}
+
+// checkSuppressed applies the suppression check of Send to an email entry.
+func (o *Outbox) checkSuppressed(ctx context.Context, entry OutboxEntry) error {
+	if entry.Kind != OutboxKindEmail {
+		return nil
+	}
+
+	var params internal.EmailSend
+	if err := json.Unmarshal(entry.Payload, &params); err != nil {
+		return nil
+	}
+
+	return o.sdk.checkSuppressed(ctx, params.ProjectId, params.User["email"], params.TypeId)
+}
+
+func (o *Outbox) append(record outboxRecord) error {
+	if err := o.journal.Append(record); err != nil {
+		return err
+	}
+	atomic.AddInt64(&o.appended, 1)
+
+	return nil
+}
+
+func (o *Outbox) update(entry OutboxEntry) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, pending := range o.pending {
+		if pending.Key == entry.Key {
+			*pending = entry
+		}
+	}
+}
+
+func (o *Outbox) remove(key string, op string, now time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for i, pending := range o.pending {
+		if pending.Key == key {
+			o.pending = append(o.pending[:i], o.pending[i+1:]...)
+			break
+		}
+	}
+	o.seen[key] = outboxKey{op: op, time: now}
+}
+
+// restore rebuilds the state from the journal and compacts it.
+func (o *Outbox) restore(records []json.RawMessage) error {
+	index := map[string]*OutboxEntry{}
+
+	for _, raw := range records {
+		var record outboxRecord
+		if err := json.Unmarshal(raw, &record); err != nil {
+			continue
+		}
+
+		switch record.Op {
+		case outboxOpEnqueue:
+			if _, ok := o.seen[record.Key]; ok || record.Entry == nil {
+				continue
+			}
+			entry := *record.Entry
+			index[record.Key] = &entry
+			o.pending = append(o.pending, &entry)
+			o.seen[record.Key] = outboxKey{op: outboxOpEnqueue, time: record.Time}
+		case outboxOpFailure:
+			if entry, ok := index[record.Key]; ok {
+				entry.Attempts++
+				entry.LastError = record.Error
+			}
+		case outboxOpDone, outboxOpDead:
+			o.seen[record.Key] = outboxKey{op: record.Op, time: record.Time}
+		}
+	}
+
+	pending := o.pending[:0]
+	for _, entry := range o.pending {
+		if o.seen[entry.Key].op == outboxOpEnqueue {
+			pending = append(pending, entry)
+		}
+	}
+	o.pending = pending
+
+	return o.compact()
+}
+
+// compact rewrites the journal with the pending entries and the keys of the finished ones,
+// so they are still deduplicated. Keys finished longer than the retention ago are forgotten.
+func (o *Outbox) compact() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	cutoff := time.Now().Add(-o.retention)
+
+	var compacted []interface{}
+	for key, seen := range o.seen {
+		if seen.op == outboxOpEnqueue {
+			continue
+		}
+		if seen.time.Before(cutoff) {
+			delete(o.seen, key)
+			continue
+		}
+		compacted = append(compacted, outboxRecord{Op: seen.op, Key: key, Time: seen.time})
+	}
+	for _, entry := range o.pending {
+		compacted = append(compacted, outboxRecord{Op: outboxOpEnqueue, Key: entry.Key, Entry: entry, Time: o.seen[entry.Key].time})
+	}
+
+	if err := o.journal.Rewrite(compacted); err != nil {
+		return err
+	}
+	atomic.StoreInt64(&o.appended, 0)
+
+	return nil
+}
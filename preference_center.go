@@ -0,0 +1,316 @@
+package go_sdk
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sendios/go-sdk/internal"
+)
+
+var (
+	ErrNoPreferenceCenterSecret = errors.New("preference center secret is empty")
+	ErrInvalidPreferenceToken   = errors.New("invalid preference center token")
+	ErrExpiredPreferenceToken   = errors.New("preference center token expired")
+)
+
+// defaultPreferenceCenterTemplate renders a PreferenceCenterPage as a plain html form.
+var defaultPreferenceCenterTemplate = template.Must(template.New("preferences").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Email preferences</title></head>
+<body>
+<h1>Email preferences</h1>
+{{if .Saved}}<p>Your preferences have been saved.</p>{{end}}
+<form method="post">
+<input type="hidden" name="token" value="{{.Token}}">
+{{range .Types}}<label><input type="checkbox" name="type" value="{{.TypeId}}"{{if .Subscribed}} checked{{end}}> {{.Name}}</label><br>
+{{end}}{{if .UnsubscribedFromAll}}<input type="hidden" name="unsubscribed_all" value="1">
+{{end}}<label><input type="checkbox" name="unsubscribe_all" value="1"{{if .UnsubscribedFromAll}} checked{{if not .CanResubscribe}} disabled{{end}}{{end}}> Unsubscribe from all emails</label><br>
+<button type="submit">Save</button>
+</form>
+</body>
+</html>
+`))
+
+// PreferenceType is an email type shown by the preference center.
+type PreferenceType struct {
+	TypeId int
+	Name   string
+}
+
+type PreferenceCenterOptions struct {
+	// Secret signs the tokens given to users, see PreferenceCenter.Token.
+	Secret []byte
+	// Types are the email types users can subscribe to and unsubscribe from, in display order.
+	Types []PreferenceType
+	// Template renders a PreferenceCenterPage, a plain html form when nil.
+	Template *template.Template
+}
+
+// PreferenceCenterPage is the data the template is executed with. The form posted back must
+// hold the token, a "type" value per subscribed type, "unsubscribe_all" when checked and
+// "unsubscribed_all" when the page showed UnsubscribedFromAll. Only a user who unsubscribed
+// from all through the settings or the email client, see CanResubscribe, can be resubscribed.
+type PreferenceCenterPage struct {
+	Token               string
+	UserId              int
+	Types               []PreferenceCenterType
+	UnsubscribedFromAll bool
+	CanResubscribe      bool
+	Saved               bool
+}
+
+type PreferenceCenterType struct {
+	TypeId     int
+	Name       string
+	Subscribed bool
+}
+
+// PreferenceCenter is an http.Handler serving an email preferences page. The user is known by
+// the signed token in the "token" parameter, e.g. from an unsubscribe link in an email:
+//
+//	center, err := sdk.NewPreferenceCenter(sendios.PreferenceCenterOptions{Secret: secret, Types: types})
+//	http.Handle("/email/preferences", center)
+//	link := "https://example.com/email/preferences?token=" + center.Token(userId, time.Now().Add(30*24*time.Hour))
+//
+// GET renders the page, POST applies the form and redirects back to the page. The types are
+// changed through UpdatePreferences, "unsubscribe from all" through UnsubEmailUserBySettings and
+// unchecking it again resubscribes the user with SubscribeEmailUser, unless the user was
+// unsubscribed another way, e.g. by an admin or a complaint.
+type PreferenceCenter struct {
+	sdk      *SendiosSdk
+	secret   []byte
+	types    []PreferenceType
+	template *template.Template
+}
+
+func (sdk *SendiosSdk) NewPreferenceCenter(opts PreferenceCenterOptions) (*PreferenceCenter, error) {
+	if len(opts.Secret) == 0 {
+		return nil, ErrNoPreferenceCenterSecret
+	}
+
+	tmpl := opts.Template
+	if tmpl == nil {
+		tmpl = defaultPreferenceCenterTemplate
+	}
+
+	return &PreferenceCenter{
+		sdk:      sdk,
+		secret:   opts.Secret,
+		types:    opts.Types,
+		template: tmpl,
+	}, nil
+}
+
+// Token returns the token giving access to the preferences of the user until expires.
+func (c *PreferenceCenter) Token(userId int, expires time.Time) string {
+	payload := fmt.Sprintf("%d.%d", userId, expires.Unix())
+
+	return payload + "." + c.sign(payload)
+}
+
+// ParseToken returns the user id of a token made by Token.
+func (c *PreferenceCenter) ParseToken(token string) (int, error) {
+	separator := strings.LastIndex(token, ".")
+	if separator < 0 || !hmac.Equal([]byte(token[separator+1:]), []byte(c.sign(token[:separator]))) {
+		return 0, ErrInvalidPreferenceToken
+	}
+
+	parts := strings.Split(token[:separator], ".")
+	if len(parts) != 2 {
+		return 0, ErrInvalidPreferenceToken
+	}
+	userId, err := strconv.Atoi(parts[0])
+	if err != nil || userId <= 0 {
+		return 0, ErrInvalidPreferenceToken
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, ErrInvalidPreferenceToken
+	}
+	if time.Now().Unix() > expires {
+		return 0, ErrExpiredPreferenceToken
+	}
+
+	return userId, nil
+}
+
+func (c *PreferenceCenter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := r.FormValue("token")
+	userId, err := c.ParseToken(token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		if err := c.save(r, userId); err != nil {
+			c.fail(w, err)
+			return
+		}
+
+		// relative to the page, so the handler works behind http.StripPrefix
+		query := url.Values{"token": {token}, "saved": {"1"}}
+		w.Header().Set("Location", "?"+query.Encode())
+		w.WriteHeader(http.StatusSeeOther)
+		return
+	}
+
+	page, err := c.page(r, token, userId)
+	if err != nil {
+		c.fail(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := c.template.Execute(w, page); err != nil {
+		http.Error(w, "error while rendering preferences", http.StatusInternalServerError)
+	}
+}
+
+func (c *PreferenceCenter) page(r *http.Request, token string, userId int) (PreferenceCenterPage, error) {
+	preferences, err := c.sdk.PreferencesByEmailUserId(r.Context(), userId)
+	if err != nil {
+		return PreferenceCenterPage{}, err
+	}
+	reason, err := c.sdk.unsubReason(r.Context(), userId)
+	if err != nil {
+		return PreferenceCenterPage{}, err
+	}
+
+	page := PreferenceCenterPage{
+		Token:               token,
+		UserId:              userId,
+		Types:               make([]PreferenceCenterType, 0, len(c.types)),
+		UnsubscribedFromAll: reason != nil,
+		CanResubscribe:      reason.canResubscribe(),
+		Saved:               r.FormValue("saved") != "",
+	}
+	for _, preferenceType := range c.types {
+		page.Types = append(page.Types, PreferenceCenterType{
+			TypeId:     preferenceType.TypeId,
+			Name:       preferenceType.Name,
+			Subscribed: !preferences.IsUnsubscribed(preferenceType.TypeId),
+		})
+	}
+
+	return page, nil
+}
+
+// save applies the posted form. Type ids which are not among the configured types are ignored.
+func (c *PreferenceCenter) save(r *http.Request, userId int) error {
+	ctx := r.Context()
+
+	checked := map[int]bool{}
+	for _, value := range r.PostForm["type"] {
+		if typeId, err := strconv.Atoi(value); err == nil {
+			checked[typeId] = true
+		}
+	}
+
+	var subscribed, unsubscribed []int
+	for _, preferenceType := range c.types {
+		if checked[preferenceType.TypeId] {
+			subscribed = append(subscribed, preferenceType.TypeId)
+		} else {
+			unsubscribed = append(unsubscribed, preferenceType.TypeId)
+		}
+	}
+
+	preferences, err := c.sdk.PreferencesByEmailUserId(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if _, err := c.sdk.UpdatePreferences(ctx, preferences, subscribed, unsubscribed); err != nil {
+		return err
+	}
+
+	reason, err := c.sdk.unsubReason(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	// only a checkbox the page showed checked can be unchecked, a form without the field
+	// resubscribes nobody
+	unsubscribeAll := r.PostForm.Get("unsubscribe_all") != ""
+	shownUnsubscribed := r.PostForm.Get("unsubscribed_all") != ""
+	switch {
+	case unsubscribeAll && reason == nil:
+		_, err = c.sdk.UnsubEmailUserBySettingsCtx(ctx, userId)
+	case !unsubscribeAll && shownUnsubscribed && reason.canResubscribe():
+		_, err = c.sdk.SubscribeEmailUserCtx(ctx, userId)
+	}
+
+	return err
+}
+
+func (c *PreferenceCenter) fail(w http.ResponseWriter, err error) {
+	if IsNotFound(err) {
+		http.Error(w, "email user not found", http.StatusNotFound)
+		return
+	}
+
+	http.Error(w, "error while updating preferences", http.StatusBadGateway)
+}
+
+func (c *PreferenceCenter) sign(payload string) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(payload))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// unsubReason is the reason of an unsubscribe from all emails, as returned by GetUnsubscribeReason.
+type unsubReason struct {
+	SourceId int    `json:"source_id"`
+	Date     string `json:"date"`
+}
+
+// canResubscribe reports whether the user unsubscribed on its own, through the settings or the
+// email client, and can undo it. Admin and complaint unsubscribes are kept.
+func (r *unsubReason) canResubscribe() bool {
+	return r != nil && (r.SourceId == SourceSettings || r.SourceId == SourceClient)
+}
+
+// unsubReason returns why the user unsubscribed from all emails, nil when subscribed.
+func (sdk *SendiosSdk) unsubReason(ctx context.Context, userId int) (*unsubReason, error) {
+	res, err := sdk.do(ctx, "GetUnsubscribeReason", http.MethodGet, sdk.apiV1Url(), internal.NewRoute("unsub/unsubreason/%d", userId), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var data struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if _, err := DecodeResponse(res, &data); err != nil {
+		return nil, err
+	}
+	if isNull(data.Result) || bytes.Equal(bytes.TrimSpace(data.Result), []byte("false")) {
+		return nil, nil
+	}
+
+	var reason unsubReason
+	if err := json.Unmarshal(data.Result, &reason); err != nil {
+		return nil, fmt.Errorf("error while decoding unsubscribe reason: %w", err)
+	}
+
+	return &reason, nil
+}
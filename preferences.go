@@ -0,0 +1,152 @@
+package go_sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var ErrConflictingPreferences = errors.New("type id is both subscribed and unsubscribed")
+
+// Preferences are the email types an email user is unsubscribed from, as returned by
+// GetUnsubListByEmailUserId. ApplyPreferences keeps them up to date with the changes it makes.
+type Preferences struct {
+	UserId       int
+	Unsubscribed []UnsubType
+}
+
+// PreferenceChange lists the type ids a user is unsubscribed from and resubscribed to.
+type PreferenceChange struct {
+	Unsubscribe []int
+	Subscribe   []int
+}
+
+func (c PreferenceChange) IsEmpty() bool {
+	return len(c.Unsubscribe) == 0 && len(c.Subscribe) == 0
+}
+
+// PreferencesByEmailUserId loads the preferences of the email user.
+func (sdk *SendiosSdk) PreferencesByEmailUserId(ctx context.Context, userId int) (*Preferences, error) {
+	types, err := sdk.UnsubTypesByEmailUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Preferences{UserId: userId, Unsubscribed: types}, nil
+}
+
+func (p *Preferences) IsUnsubscribed(typeId int) bool {
+	for _, unsubType := range p.Unsubscribed {
+		if unsubType.TypeId == typeId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UnsubscribedTypeIds returns the sorted ids of the types the user is unsubscribed from.
+func (p *Preferences) UnsubscribedTypeIds() []int {
+	typeIds := make([]int, 0, len(p.Unsubscribed))
+	for _, unsubType := range p.Unsubscribed {
+		typeIds = append(typeIds, unsubType.TypeId)
+	}
+	sort.Ints(typeIds)
+
+	return typeIds
+}
+
+// Diff returns the change which makes the user subscribed to the subscribed type ids and
+// unsubscribed from the unsubscribed ones, leaving out the types already in that state.
+// Types in neither list are left as they are.
+func (p *Preferences) Diff(subscribed []int, unsubscribed []int) (PreferenceChange, error) {
+	var change PreferenceChange
+
+	wanted := map[int]bool{}
+	for _, typeId := range unsubscribed {
+		wanted[typeId] = true
+	}
+	for _, typeId := range subscribed {
+		if wanted[typeId] {
+			return PreferenceChange{}, fmt.Errorf("%w: %d", ErrConflictingPreferences, typeId)
+		}
+	}
+
+	for _, typeId := range uniqueTypeIds(nil, unsubscribed) {
+		if !p.IsUnsubscribed(typeId) {
+			change.Unsubscribe = append(change.Unsubscribe, typeId)
+		}
+	}
+	for _, typeId := range uniqueTypeIds(nil, subscribed) {
+		if p.IsUnsubscribed(typeId) {
+			change.Subscribe = append(change.Subscribe, typeId)
+		}
+	}
+
+	return change, nil
+}
+
+// ApplyPreferences makes the change to the preferences with a single request: the nodiff routes when the change only adds
+// or only removes types, RemoveAllUnsubTypesByEmailUser when every type is resubscribed and
+// UnsubEmailUserByTypes with the resulting list otherwise. Types already in the wanted state
+// are skipped, the returned change is what was actually applied.
+//
+// The preferences are not reloaded first, changes made elsewhere since they were loaded may be
+// overwritten when the whole list is replaced.
+func (sdk *SendiosSdk) ApplyPreferences(ctx context.Context, p *Preferences, change PreferenceChange) (PreferenceChange, error) {
+	change, err := p.Diff(change.Subscribe, change.Unsubscribe)
+	if err != nil || change.IsEmpty() {
+		return change, err
+	}
+
+	next := p.next(change)
+
+	switch {
+	case len(change.Subscribe) == 0:
+		_, err = sdk.AddTypesToUnsubByEmailUserCtx(ctx, p.UserId, change.Unsubscribe)
+	case len(next) == 0:
+		_, err = sdk.RemoveAllUnsubTypesByEmailUserCtx(ctx, p.UserId)
+	case len(change.Unsubscribe) == 0:
+		_, err = sdk.RemoveUnsubTypesByEmailUserCtx(ctx, p.UserId, change.Subscribe)
+	default:
+		typeIds := make([]int, 0, len(next))
+		for _, unsubType := range next {
+			typeIds = append(typeIds, unsubType.TypeId)
+		}
+		_, err = sdk.UnsubEmailUserByTypesCtx(ctx, p.UserId, typeIds)
+	}
+	if err != nil {
+		return PreferenceChange{}, fmt.Errorf("error while applying preferences: %w", err)
+	}
+
+	p.Unsubscribed = next
+
+	return change, nil
+}
+
+// UpdatePreferences applies the change which makes the user subscribed to the subscribed type
+// ids and unsubscribed from the unsubscribed ones, see Diff and ApplyPreferences.
+func (sdk *SendiosSdk) UpdatePreferences(ctx context.Context, p *Preferences, subscribed []int, unsubscribed []int) (PreferenceChange, error) {
+
+	return sdk.ApplyPreferences(ctx, p, PreferenceChange{Subscribe: subscribed, Unsubscribe: unsubscribed})
+}
+
+func (p *Preferences) next(change PreferenceChange) []UnsubType {
+	resubscribed := map[int]bool{}
+	for _, typeId := range change.Subscribe {
+		resubscribed[typeId] = true
+	}
+
+	next := []UnsubType{}
+	for _, unsubType := range p.Unsubscribed {
+		if !resubscribed[unsubType.TypeId] {
+			next = append(next, unsubType)
+		}
+	}
+	for _, typeId := range change.Unsubscribe {
+		next = append(next, UnsubType{TypeId: typeId})
+	}
+
+	return next
+}
@@ -0,0 +1,138 @@
+package go_sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sendios/go-sdk/internal"
+)
+
+const (
+	DefaultPushUserCacheSize   = 10000
+	DefaultPushUserCacheTTL    = time.Minute * 5
+	DefaultPushUserNegativeTTL = time.Second * 30
+)
+
+// pushUserCache keeps the push users resolved by the push methods taking an email user id
+// or a project id and hash. Lookups which found no push user are kept for negativeTTL.
+type pushUserCache struct {
+	cache       *internal.LRUCache
+	negativeTTL time.Duration
+}
+
+type pushUserEntry struct {
+	pushUser  PushUser
+	err       error
+	projectId int
+}
+
+func newPushUserCache(size int, ttl time.Duration, negativeTTL time.Duration) *pushUserCache {
+	if size <= 0 {
+		return nil
+	}
+
+	return &pushUserCache{cache: internal.NewLRUCache(size, ttl), negativeTTL: negativeTTL}
+}
+
+func pushUserIdKey(userId int) string {
+	return fmt.Sprintf("user/%d", userId)
+}
+
+func pushUserHashKey(projectId int, hash string) string {
+	return fmt.Sprintf("hash/%d/%s", projectId, hash)
+}
+
+// pushUserMissTag tags the lookups of a project which found no push user.
+func pushUserMissTag(projectId int) string {
+	return fmt.Sprintf("miss/%d", projectId)
+}
+
+func (c *pushUserCache) get(key string) (pushUserEntry, bool) {
+	if c == nil {
+		return pushUserEntry{}, false
+	}
+
+	entry, ok := c.cache.Get(key)
+	if !ok {
+		return pushUserEntry{}, false
+	}
+
+	return entry.(pushUserEntry), true
+}
+
+// set stores a resolved push user, or ErrPushUserNotFound for negativeTTL. Other errors are not cached.
+func (c *pushUserCache) set(key string, entry pushUserEntry) {
+	if c == nil {
+		return
+	}
+
+	if entry.err == nil {
+		c.cache.Set(key, entry)
+		return
+	}
+
+	if errors.Is(entry.err, ErrPushUserNotFound) && c.negativeTTL > 0 {
+		c.cache.SetTaggedWithTTL(key, entry, pushUserMissTag(entry.projectId), c.negativeTTL)
+	}
+}
+
+// forget removes the push user of userId and the lookups of the project which found nothing,
+// as a push user was created for them.
+func (c *pushUserCache) forget(userId int, projectId int) {
+	if c == nil {
+		return
+	}
+
+	c.cache.Delete(pushUserIdKey(userId))
+	c.cache.DeleteTag(pushUserMissTag(projectId))
+}
+
+func (sdk *SendiosSdk) resolvePushUserById(ctx context.Context, userId int) (PushUser, error) {
+	key := pushUserIdKey(userId)
+	if entry, ok := sdk.pushUsers.get(key); ok {
+		return entry.pushUser, entry.err
+	}
+
+	res, err := sdk.GetPushUserByIdCtx(ctx, userId)
+	entry := decodePushUser(res, err)
+	sdk.pushUsers.set(key, entry)
+
+	return entry.pushUser, entry.err
+}
+
+func (sdk *SendiosSdk) resolvePushUserByHash(ctx context.Context, projectId int, hash string) (PushUser, error) {
+	key := pushUserHashKey(projectId, hash)
+	if entry, ok := sdk.pushUsers.get(key); ok {
+		return entry.pushUser, entry.err
+	}
+
+	res, err := sdk.GetPushUserByProjectIdAndHashCtx(ctx, projectId, hash)
+	entry := decodePushUser(res, err)
+	entry.projectId = projectId
+	sdk.pushUsers.set(key, entry)
+
+	return entry.pushUser, entry.err
+}
+
+// decodePushUser turns the response of a push user lookup into a cache entry, reporting
+// missing push users as ErrPushUserNotFound.
+func decodePushUser(res []byte, err error) pushUserEntry {
+	if IsNotFound(err) {
+		err = &notFoundError{err: ErrPushUserNotFound, cause: err}
+	}
+	if err != nil {
+		return pushUserEntry{err: fmt.Errorf("error while getting push user: %w", err)}
+	}
+
+	pushUser, err := parsePushUserFromResponseData(res)
+	if err != nil {
+		return pushUserEntry{err: fmt.Errorf("error while parsing push user: %w", err)}
+	}
+	if pushUser.Id == 0 {
+		return pushUserEntry{err: fmt.Errorf("error while getting push user: %w", &notFoundError{err: ErrPushUserNotFound})}
+	}
+
+	return pushUserEntry{pushUser: pushUser, projectId: pushUser.ProjectId}
+}
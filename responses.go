@@ -0,0 +1,225 @@
+package go_sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sendios/go-sdk/internal"
+)
+
+const (
+	MetaStatusSuccess = "SUCCESS"
+	MetaStatusError   = "ERROR"
+)
+
+// Meta is the "_meta" block every api response carries.
+type Meta = internal.Meta
+
+// Response is the envelope of every api response. Data is left undecoded so fields the sdk
+// does not know about yet stay reachable.
+type Response struct {
+	Meta Meta            `json:"_meta"`
+	Data json.RawMessage `json:"data"`
+}
+
+type EmailUser struct {
+	Id               int                   `json:"id"`
+	Email            string                `json:"email"`
+	ProjectId        int                   `json:"project_id"`
+	ProjectTitle     string                `json:"project_title"`
+	Name             string                `json:"name"`
+	Gender           string                `json:"gender"`
+	Language         string                `json:"language"`
+	CreatedAt        string                `json:"created_at"`
+	Clicks           int                   `json:"clicks"`
+	Sends            int                   `json:"sends"`
+	UnsubscribeTypes []UserUnsubscribeType `json:"unsubscribe_types"`
+}
+
+type UserUnsubscribeType struct {
+	TypeId    int    `json:"type_id"`
+	CreatedAt string `json:"created_at"`
+}
+
+type PushUser struct {
+	Id        int               `json:"id"`
+	UserId    int               `json:"user_id"`
+	ProjectId int               `json:"project_id"`
+	Hash      string            `json:"hash"`
+	RegDate   string            `json:"reg_date"`
+	LastPush  string            `json:"last_push"`
+	Meta      map[string]string `json:"meta"`
+}
+
+type UnsubStatus struct {
+	Unsubscribed bool `json:"result"`
+}
+
+// UnsubEvent is an unsubscribe as listed by GetUnsubscribesByDate. The api does not publish
+// the schema of the list, so the fields are read under the names the api uses for the same
+// values elsewhere: "user_id" or "id", "email", "project_id", "source_id" and "date" or
+// "created_at", formatted as "2006-01-02 15:04:05" like the other dates and read as UTC.
+// Timestamp is the unix "timestamp" when listed, the parsed date otherwise. Raw keeps the
+// listed item for any other field.
+type UnsubEvent struct {
+	UserId    int             `json:"user_id"`
+	Email     string          `json:"email"`
+	ProjectId int             `json:"project_id"`
+	SourceId  int             `json:"source_id"`
+	Date      string          `json:"date"`
+	Timestamp int64           `json:"timestamp"`
+	Raw       json.RawMessage `json:"-"`
+}
+
+// unsubDateFormat is the format of the dates in api responses, such as created_at.
+const unsubDateFormat = "2006-01-02 15:04:05"
+
+// UnmarshalJSON fails for an item without a date, as the feed could not order it.
+func (e *UnsubEvent) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		UserId    int    `json:"user_id"`
+		Id        int    `json:"id"`
+		Email     string `json:"email"`
+		ProjectId int    `json:"project_id"`
+		SourceId  int    `json:"source_id"`
+		Date      string `json:"date"`
+		CreatedAt string `json:"created_at"`
+		Timestamp int64  `json:"timestamp"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*e = UnsubEvent{
+		UserId:    raw.UserId,
+		Email:     raw.Email,
+		ProjectId: raw.ProjectId,
+		SourceId:  raw.SourceId,
+		Date:      raw.Date,
+		Timestamp: raw.Timestamp,
+		Raw:       append(json.RawMessage(nil), data...),
+	}
+	if e.UserId == 0 {
+		e.UserId = raw.Id
+	}
+	if e.Date == "" {
+		e.Date = raw.CreatedAt
+	}
+
+	if e.Timestamp == 0 {
+		date, err := time.ParseInLocation(unsubDateFormat, e.Date, time.UTC)
+		if err != nil {
+			return fmt.Errorf("error while parsing unsubscribe date %q: %w", e.Date, err)
+		}
+		e.Timestamp = date.Unix()
+	}
+
+	return nil
+}
+
+type UnsubType struct {
+	TypeId    int    `json:"type_id"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+}
+
+// UserFields holds the user record and the project specific custom fields of a user.
+type UserFields struct {
+	User         map[string]interface{} `json:"user"`
+	CustomFields map[string]interface{} `json:"custom_fields"`
+}
+
+func (f *UserFields) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		User         json.RawMessage `json:"user"`
+		CustomFields json.RawMessage `json:"custom_fields"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	if err := unmarshalObject(raw.User, &f.User); err != nil {
+		return fmt.Errorf("error while unmarshaling user: %w", err)
+	}
+
+	if err := unmarshalObject(raw.CustomFields, &f.CustomFields); err != nil {
+		return fmt.Errorf("error while unmarshaling custom fields: %w", err)
+	}
+
+	return nil
+}
+
+type CheckEmailResult struct {
+	Email   string `json:"email"`
+	Orig    string `json:"orig"`
+	Domain  string `json:"domain"`
+	Vendor  string `json:"vendor"`
+	Reason  string `json:"reason"`
+	Valid   bool   `json:"valid"`
+	Trusted bool   `json:"trusted"`
+}
+
+type BuyingDecision struct {
+	Email    string `json:"email"`
+	Decision bool   `json:"decision"`
+}
+
+// DecodeResponse decodes the "data" block of a raw response into data. Responses with
+// the ERROR meta status are returned as *APIError.
+func DecodeResponse(raw []byte, data interface{}) (Meta, error) {
+	meta, err := decodeEnvelope(raw, data)
+	if err != nil {
+		return meta, err
+	}
+
+	if meta.Status == MetaStatusError {
+		return meta, internal.NewAPIError("", "", http.StatusOK, raw)
+	}
+
+	return meta, nil
+}
+
+type emailUserData struct {
+	User EmailUser `json:"user"`
+}
+
+type pushUserData struct {
+	PushUser PushUser `json:"result"`
+}
+
+type userFieldsData struct {
+	UserFields UserFields `json:"result"`
+}
+
+func decodeEnvelope(raw []byte, data interface{}) (Meta, error) {
+	var response Response
+	if err := json.Unmarshal(raw, &response); err != nil {
+		return Meta{}, err
+	}
+
+	if response.Meta.Status == MetaStatusError || data == nil || isNull(response.Data) {
+		return response.Meta, nil
+	}
+
+	return response.Meta, json.Unmarshal(response.Data, data)
+}
+
+// unmarshalObject tolerates the empty json array the api sends in place of an empty object.
+func unmarshalObject(raw json.RawMessage, target *map[string]interface{}) error {
+	trimmed := bytes.TrimSpace(raw)
+	if isNull(trimmed) || bytes.Equal(trimmed, []byte("[]")) {
+		*target = map[string]interface{}{}
+		return nil
+	}
+
+	return json.Unmarshal(trimmed, target)
+}
+
+func isNull(raw json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(raw)
+
+	return len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null"))
+}
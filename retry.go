@@ -0,0 +1,25 @@
+package go_sdk
+
+import (
+	"context"
+
+	"github.com/sendios/go-sdk/internal"
+)
+
+// RetryPolicy controls how transient failures (network errors and the RetryableStatuses)
+// are retried. GET, PUT and DELETE requests are always retried, POST requests only
+// when the context carries an idempotency key, see ContextWithIdempotencyKey.
+type RetryPolicy = internal.RetryPolicy
+
+func DefaultRetryPolicy() RetryPolicy {
+
+	return internal.DefaultRetryPolicy()
+}
+
+// ContextWithIdempotencyKey attaches an idempotency key to every request made with the
+// returned context. The key is sent as the Idempotency-Key header and allows POST
+// requests such as SendEmail to be retried safely.
+func ContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+
+	return internal.ContextWithIdempotencyKey(ctx, key)
+}
@@ -1,11 +1,13 @@
 package go_sdk
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"github.com/sendios/go-sdk/internal"
 	"net/http"
+	"sync"
 	"time"
+
+	"github.com/sendios/go-sdk/internal"
 )
 
 const (
@@ -28,6 +30,13 @@ const (
 	SourceSettings = 9
 )
 
+// EncryptionKeyEnv names the environment variable holding the key used to encrypt template data.
+const EncryptionKeyEnv = "SENDIOS_ENCRYPTION_KEY"
+
+// legacyEncryptionKey is used for template data when no key is configured and the sdk opted in with
+// WithLegacyEncryptionKey. It is the publicly known key of the first sdk releases.
+const legacyEncryptionKey = "444500b1bd43b59f"
+
 const (
 	ApiV3 = "https://api.sendios.io/v3/"
 	ApiV1 = "https://api.sendios.io/v1/"
@@ -36,210 +45,363 @@ const (
 var m = map[int]string{System: "push/system", Trigger: "push/trigger"}
 
 type SendiosSdk struct {
-	Request *internal.Request
+	Request       *internal.Request
+	apiV1         string
+	apiV3         string
+	encryptionKey []byte
+	legacyKey     bool
+	legacyKeyOnce sync.Once
+	userIds       UserIdCache
+	pushUsers     *pushUserCache
+
+	suppressions      *SuppressionList
+	checkSuppressions bool
 }
 
-func NewSendiosSdk(clientId string, authKey string) *SendiosSdk {
+func NewSendiosSdk(clientId string, authKey string, opts ...Option) *SendiosSdk {
+	cfg := newConfig(opts)
 	auth := &internal.Auth{ClientId: clientId, AuthKey: authKey}
-	client := &http.Client{Timeout: time.Second * 10}
 
-	r := &internal.Request{Client: client, Auth: auth}
+	r := &internal.Request{
+		Client:     cfg.httpClient(),
+		Auth:       auth,
+		UserAgent:  cfg.userAgent,
+		Retry:      &cfg.retry,
+		Limiter:    cfg.limiter,
+		Middleware: cfg.middleware,
+		Logger:     cfg.logger,
+		Metrics:    cfg.metrics,
+		Tracer:     cfg.tracer,
+	}
 	sdk := SendiosSdk{
-		Request: r,
+		Request:       r,
+		apiV1:         cfg.apiV1,
+		apiV3:         cfg.apiV3,
+		encryptionKey: cfg.encryptionKey,
+		legacyKey:     cfg.legacyEncryptionKey,
+		userIds:       cfg.userIds,
+		pushUsers:     newPushUserCache(cfg.pushUserCacheSize, cfg.pushUserCacheTTL, cfg.pushUserNegativeTTL),
+
+		suppressions:      cfg.suppressions,
+		checkSuppressions: cfg.checkSuppressions,
 	}
 
 	return &sdk
 }
 
 func (sdk *SendiosSdk) GetBuyingDecisions(email string) ([]byte, error) {
+
+	return sdk.GetBuyingDecisionsCtx(context.Background(), email)
+}
+
+func (sdk *SendiosSdk) GetBuyingDecisionsCtx(ctx context.Context, email string) ([]byte, error) {
 	params := internal.BuyingDecisionData{Email: email}
 
-	return sdk.Request.Post(ApiV1, "buying/email", params)
+	return sdk.do(ctx, "GetBuyingDecisions", http.MethodPost, sdk.apiV1Url(), internal.NewRoute("buying/email"), params)
 }
 
 func (sdk *SendiosSdk) CreateClientUser(email string, clientUserId string, projectId int) ([]byte, error) {
+
+	return sdk.CreateClientUserCtx(context.Background(), email, clientUserId, projectId)
+}
+
+func (sdk *SendiosSdk) CreateClientUserCtx(ctx context.Context, email string, clientUserId string, projectId int) ([]byte, error) {
 	params := internal.ClientUser{Email: email, ClientUserId: clientUserId, ProjectId: projectId}
 
-	return sdk.Request.Post(ApiV1, "clientuser/create", params)
+	return sdk.do(ctx, "CreateClientUser", http.MethodPost, sdk.apiV1Url(), internal.NewRoute("clientuser/create"), params, internal.WithProjectId(projectId))
 }
 
 func (sdk *SendiosSdk) CheckEmail(email string, sanitize bool) ([]byte, error) {
+
+	return sdk.CheckEmailCtx(context.Background(), email, sanitize)
+}
+
+func (sdk *SendiosSdk) CheckEmailCtx(ctx context.Context, email string, sanitize bool) ([]byte, error) {
 	params := internal.CheckEmail{Email: email, Sanitize: sanitize}
 
-	return sdk.Request.Post(ApiV1, "email/check", params)
+	return sdk.do(ctx, "CheckEmail", http.MethodPost, sdk.apiV1Url(), internal.NewRoute("email/check"), params)
 }
 
 func (sdk *SendiosSdk) ValidateEmail(email string, projectId int) ([]byte, error) {
+
+	return sdk.ValidateEmailCtx(context.Background(), email, projectId)
+}
+
+func (sdk *SendiosSdk) ValidateEmailCtx(ctx context.Context, email string, projectId int) ([]byte, error) {
 	params := internal.ValidateEmail{Email: email, ProjectId: projectId}
 
-	return sdk.Request.Post(ApiV1, "email/check/send", params)
+	return sdk.do(ctx, "ValidateEmail", http.MethodPost, sdk.apiV1Url(), internal.NewRoute("email/check/send"), params, internal.WithProjectId(projectId))
 }
 
 func (sdk *SendiosSdk) TrackClickByMailId(mailId int) ([]byte, error) {
 
-	return sdk.Request.Post(ApiV1, fmt.Sprintf("trackemail/click/%d", mailId), nil)
+	return sdk.TrackClickByMailIdCtx(context.Background(), mailId)
 }
 
-func (sdk *SendiosSdk) ProdEventSend(data interface{}) ([]byte, error) {
+func (sdk *SendiosSdk) TrackClickByMailIdCtx(ctx context.Context, mailId int) ([]byte, error) {
 
-	return sdk.Request.Post(ApiV1, "product-event/create", data)
+	return sdk.do(ctx, "TrackClickByMailId", http.MethodPost, sdk.apiV1Url(), internal.NewRoute("trackemail/click/%d", mailId), nil)
 }
 
-func (sdk *SendiosSdk) SendEmail(clientId int, typeId int, categoryId int, projectId int, email string, user map[string]string, data map[string]string, meta map[string]string) ([]byte, error) {
-	user["email"] = email
-	jsonString, err := json.Marshal(data)
+func (sdk *SendiosSdk) ProdEventSend(data interface{}) ([]byte, error) {
 
-	if err != nil {
-		return nil, fmt.Errorf("error while json marshaling: %s", err)
-	}
+	return sdk.ProdEventSendCtx(context.Background(), data)
+}
 
-	encrypter, err := internal.MakeEncrypt()
-	if err != nil {
-		return nil, fmt.Errorf("error while encrypting: %s", err)
-	}
+func (sdk *SendiosSdk) ProdEventSendCtx(ctx context.Context, data interface{}) ([]byte, error) {
 
-	encrypt, err := encrypter.EncryptData(jsonString)
-	if err != nil {
-		return nil, fmt.Errorf("error data encrypting: %s", err)
-	}
+	return sdk.do(ctx, "ProdEventSend", http.MethodPost, sdk.apiV1Url(), internal.NewRoute("product-event/create"), data)
+}
 
-	params := internal.EmailSend{
-		TypeId:       typeId,
-		Category:     categoryId,
-		ProjectId:    projectId,
-		ClientId:     clientId,
-		User:         user,
-		Meta:         meta,
-		ValueEncrypt: internal.ValueEncrypt{TemplateData: encrypt},
-	}
+func (sdk *SendiosSdk) SendEmail(clientId int, typeId int, categoryId int, projectId int, email string, user map[string]string, data map[string]string, meta map[string]string) ([]byte, error) {
 
-	route, err := getRoute(categoryId)
-	if err != nil {
-		return nil, fmt.Errorf("error while getting route: %s", err)
+	return sdk.SendEmailCtx(context.Background(), clientId, typeId, categoryId, projectId, email, user, data, meta)
+}
+
+func (sdk *SendiosSdk) SendEmailCtx(ctx context.Context, clientId int, typeId int, categoryId int, projectId int, email string, user map[string]string, data map[string]string, meta map[string]string) ([]byte, error) {
+	msg := EmailMessage{
+		ClientId:  clientId,
+		TypeId:    typeId,
+		Category:  categoryId,
+		ProjectId: projectId,
+		Email:     email,
+		User:      user,
+		Data:      data,
+		Meta:      meta,
 	}
 
-	return sdk.Request.Post(ApiV1, route, params)
+	return sdk.sendEmail(ctx, msg)
 }
 
 func (sdk *SendiosSdk) GetUnsubListByEmailUserId(userId int) ([]byte, error) {
 
-	return sdk.Request.Get(ApiV1, fmt.Sprintf("unsubtypes/%d", userId))
+	return sdk.GetUnsubListByEmailUserIdCtx(context.Background(), userId)
+}
+
+func (sdk *SendiosSdk) GetUnsubListByEmailUserIdCtx(ctx context.Context, userId int) ([]byte, error) {
+
+	return sdk.do(ctx, "GetUnsubListByEmailUserId", http.MethodGet, sdk.apiV1Url(), internal.NewRoute("unsubtypes/%d", userId), nil)
 }
 
 func (sdk *SendiosSdk) UnsubEmailUserByTypes(userId int, typeIds []int) ([]byte, error) {
+
+	return sdk.UnsubEmailUserByTypesCtx(context.Background(), userId, typeIds)
+}
+
+func (sdk *SendiosSdk) UnsubEmailUserByTypesCtx(ctx context.Context, userId int, typeIds []int) ([]byte, error) {
 	params := internal.TypeIds{TypeIds: typeIds}
+	res, err := sdk.do(ctx, "UnsubEmailUserByTypes", http.MethodPost, sdk.apiV1Url(), internal.NewRoute("unsubtypes/%d", userId), params)
 
-	return sdk.Request.Post(ApiV1, fmt.Sprintf("%s/%d", "unsubtypes", userId), params)
+	return sdk.suppressAfter(ctx, res, err, func(list *SuppressionList) error {
+		return list.update(list.setTypes(userId, typeIds), list.add(sdk.knownUser(userId)))
+	})
 }
 
 func (sdk *SendiosSdk) AddTypesToUnsubByEmailUser(userId int, typeIds []int) ([]byte, error) {
+
+	return sdk.AddTypesToUnsubByEmailUserCtx(context.Background(), userId, typeIds)
+}
+
+func (sdk *SendiosSdk) AddTypesToUnsubByEmailUserCtx(ctx context.Context, userId int, typeIds []int) ([]byte, error) {
 	params := internal.TypeIds{TypeIds: typeIds}
+	res, err := sdk.do(ctx, "AddTypesToUnsubByEmailUser", http.MethodPost, sdk.apiV1Url(), internal.NewRoute("unsubtypes/nodiff/%d", userId), params)
 
-	return sdk.Request.Post(ApiV1, fmt.Sprintf("%s/%d", "unsubtypes/nodiff", userId), params)
+	return sdk.suppressAfter(ctx, res, err, func(list *SuppressionList) error {
+		return list.Add(Suppression{UserId: userId, TypeIds: typeIds}, sdk.knownUser(userId))
+	})
 }
 
 func (sdk *SendiosSdk) RemoveUnsubTypesByEmailUser(userId int, typeIds []int) ([]byte, error) {
+
+	return sdk.RemoveUnsubTypesByEmailUserCtx(context.Background(), userId, typeIds)
+}
+
+func (sdk *SendiosSdk) RemoveUnsubTypesByEmailUserCtx(ctx context.Context, userId int, typeIds []int) ([]byte, error) {
 	params := internal.TypeIds{TypeIds: typeIds}
+	res, err := sdk.do(ctx, "RemoveUnsubTypesByEmailUser", http.MethodDelete, sdk.apiV1Url(), internal.NewRoute("unsubtypes/nodiff/%d", userId), params)
 
-	return sdk.Request.Delete(ApiV1, fmt.Sprintf("unsubtypes/nodiff/%d", userId), params)
+	return sdk.suppressAfter(ctx, res, err, func(list *SuppressionList) error {
+		return list.RemoveTypes(userId, typeIds)
+	})
 }
 
 func (sdk *SendiosSdk) RemoveAllUnsubTypesByEmailUser(userId int) ([]byte, error) {
 
-	return sdk.Request.Delete(ApiV1, fmt.Sprintf("unsubtypes/all/%d", userId), nil)
+	return sdk.RemoveAllUnsubTypesByEmailUserCtx(context.Background(), userId)
+}
+
+func (sdk *SendiosSdk) RemoveAllUnsubTypesByEmailUserCtx(ctx context.Context, userId int) ([]byte, error) {
+	res, err := sdk.do(ctx, "RemoveAllUnsubTypesByEmailUser", http.MethodDelete, sdk.apiV1Url(), internal.NewRoute("unsubtypes/all/%d", userId), nil)
+
+	return sdk.suppressAfter(ctx, res, err, func(list *SuppressionList) error {
+		return list.RemoveAllTypes(userId)
+	})
 }
 
 func (sdk *SendiosSdk) UnsubEmailUserClient(userId int) ([]byte, error) {
 
-	return sdk.addEmailUserToUnsubList(userId, SourceClient)
+	return sdk.UnsubEmailUserClientCtx(context.Background(), userId)
+}
+
+func (sdk *SendiosSdk) UnsubEmailUserClientCtx(ctx context.Context, userId int) ([]byte, error) {
+
+	return sdk.addEmailUserToUnsubList(ctx, "UnsubEmailUserClient", userId, SourceClient)
 }
 
 func (sdk *SendiosSdk) UnsubEmailUserBySettings(userId int) ([]byte, error) {
 
-	return sdk.addEmailUserToUnsubList(userId, SourceSettings)
+	return sdk.UnsubEmailUserBySettingsCtx(context.Background(), userId)
+}
+
+func (sdk *SendiosSdk) UnsubEmailUserBySettingsCtx(ctx context.Context, userId int) ([]byte, error) {
+
+	return sdk.addEmailUserToUnsubList(ctx, "UnsubEmailUserBySettings", userId, SourceSettings)
 }
 
 func (sdk *SendiosSdk) UnsubEmailUserByAdmin(email string, projectId int) ([]byte, error) {
+
+	return sdk.UnsubEmailUserByAdminCtx(context.Background(), email, projectId)
+}
+
+func (sdk *SendiosSdk) UnsubEmailUserByAdminCtx(ctx context.Context, email string, projectId int) ([]byte, error) {
+	sdk.forgetEmail(ctx, projectId, email)
 	encodedEmail := internal.Base64Encoder(email)
+	res, err := sdk.do(ctx, "UnsubEmailUserByAdmin", http.MethodPost, sdk.apiV1Url(), internal.NewRoute("unsub/admin/%d/email/%s", projectId, encodedEmail), nil, internal.WithProjectId(projectId))
 
-	return sdk.Request.Post(ApiV1, fmt.Sprintf("unsub/admin/%d/email/%s", projectId, encodedEmail), nil)
+	return sdk.suppressAfter(ctx, res, err, func(list *SuppressionList) error {
+		return list.Add(Suppression{ProjectId: projectId, Email: email, All: true})
+	})
 }
 
 func (sdk *SendiosSdk) SubscribeEmailUser(userId int) ([]byte, error) {
 
-	return sdk.Request.Delete(ApiV1, fmt.Sprintf("unsub/%d", userId), nil)
+	return sdk.SubscribeEmailUserCtx(context.Background(), userId)
+}
+
+func (sdk *SendiosSdk) SubscribeEmailUserCtx(ctx context.Context, userId int) ([]byte, error) {
+	sdk.forgetUser(ctx, userId)
+	res, err := sdk.do(ctx, "SubscribeEmailUser", http.MethodDelete, sdk.apiV1Url(), internal.NewRoute("unsub/%d", userId), nil)
+
+	return sdk.suppressAfter(ctx, res, err, func(list *SuppressionList) error {
+		return list.Resubscribe(userId)
+	})
 }
 
 func (sdk *SendiosSdk) IsUnsubUser(userId int) ([]byte, error) {
 
-	return sdk.Request.Get(ApiV1, fmt.Sprintf("unsub/isunsub/%d", userId))
+	return sdk.IsUnsubUserCtx(context.Background(), userId)
+}
+
+func (sdk *SendiosSdk) IsUnsubUserCtx(ctx context.Context, userId int) ([]byte, error) {
+
+	return sdk.do(ctx, "IsUnsubUser", http.MethodGet, sdk.apiV1Url(), internal.NewRoute("unsub/isunsub/%d", userId), nil)
 }
 
 func (sdk *SendiosSdk) IsUnsubByEmailAndProjectId(email string, projectId int) ([]byte, error) {
-	res, err := sdk.GetEmailUserByEmailAndProjectId(email, projectId)
-	if err != nil {
-		return nil, fmt.Errorf("can not get email user by project %d and email %s. Error: %s", projectId, email, err)
-	}
 
-	user, err := parseUserFromResponseData(res)
+	return sdk.IsUnsubByEmailAndProjectIdCtx(context.Background(), email, projectId)
+}
+
+func (sdk *SendiosSdk) IsUnsubByEmailAndProjectIdCtx(ctx context.Context, email string, projectId int) ([]byte, error) {
+	userId, err := sdk.resolveUserId(ctx, email, projectId)
 	if err != nil {
-		return nil, fmt.Errorf("error while email user parsing: %s", err)
+		return nil, fmt.Errorf("can not get email user by project %d and email %s. Error: %w", projectId, email, err)
 	}
 
-	return sdk.Request.Get(ApiV1, fmt.Sprintf("unsub/isunsub/%d", user.Id))
+	return sdk.do(ctx, "IsUnsubByEmailAndProjectId", http.MethodGet, sdk.apiV1Url(), internal.NewRoute("unsub/isunsub/%d", userId), nil, internal.WithProjectId(projectId))
 }
 
 func (sdk *SendiosSdk) GetUnsubscribeReason(email string, projectId int) ([]byte, error) {
-	res, err := sdk.GetEmailUserByEmailAndProjectId(email, projectId)
-	if err != nil {
-		return nil, fmt.Errorf("can not get email user by project %d and email %s. Error: %s", projectId, email, err)
-	}
 
-	user, err := parseUserFromResponseData(res)
+	return sdk.GetUnsubscribeReasonCtx(context.Background(), email, projectId)
+}
+
+func (sdk *SendiosSdk) GetUnsubscribeReasonCtx(ctx context.Context, email string, projectId int) ([]byte, error) {
+	userId, err := sdk.resolveUserId(ctx, email, projectId)
 	if err != nil {
-		return nil, fmt.Errorf("error while email user parsing: %s", err)
+		return nil, fmt.Errorf("can not get email user by project %d and email %s. Error: %w", projectId, email, err)
 	}
 
-	return sdk.Request.Get(ApiV1, fmt.Sprintf("unsub/unsubreason/%d", user.Id))
+	return sdk.do(ctx, "GetUnsubscribeReason", http.MethodGet, sdk.apiV1Url(), internal.NewRoute("unsub/unsubreason/%d", userId), nil, internal.WithProjectId(projectId))
 }
 
 func (sdk *SendiosSdk) GetUnsubscribesByDate(time int64) ([]byte, error) {
 
-	return sdk.Request.Get(ApiV1, fmt.Sprintf("unsub/list/%d", time))
+	return sdk.GetUnsubscribesByDateCtx(context.Background(), time)
+}
+
+func (sdk *SendiosSdk) GetUnsubscribesByDateCtx(ctx context.Context, time int64) ([]byte, error) {
+
+	return sdk.do(ctx, "GetUnsubscribesByDate", http.MethodGet, sdk.apiV1Url(), internal.NewRoute("unsub/list/%d", time), nil)
 }
 
 func (sdk *SendiosSdk) GetEmailUserByEmailAndProjectId(email string, projectId int) ([]byte, error) {
 
-	return sdk.Request.Get(ApiV1, fmt.Sprintf("user/project/%d/email/%s", projectId, email))
+	return sdk.GetEmailUserByEmailAndProjectIdCtx(context.Background(), email, projectId)
+}
+
+func (sdk *SendiosSdk) GetEmailUserByEmailAndProjectIdCtx(ctx context.Context, email string, projectId int) ([]byte, error) {
+
+	return sdk.do(ctx, "GetEmailUserByEmailAndProjectId", http.MethodGet, sdk.apiV1Url(), internal.NewRoute("user/project/%d/email/%s", projectId, email), nil, internal.WithProjectId(projectId))
 }
 
 func (sdk *SendiosSdk) GetEmailUserById(id int) ([]byte, error) {
 
-	return sdk.Request.Get(ApiV1, fmt.Sprintf("user/id/%d", id))
+	return sdk.GetEmailUserByIdCtx(context.Background(), id)
+}
+
+func (sdk *SendiosSdk) GetEmailUserByIdCtx(ctx context.Context, id int) ([]byte, error) {
+
+	return sdk.do(ctx, "GetEmailUserById", http.MethodGet, sdk.apiV1Url(), internal.NewRoute("user/id/%d", id), nil)
 }
 
 func (sdk *SendiosSdk) SetUserFieldsByEmailAndProjectId(email string, projectId int, data map[string]string) ([]byte, error) {
+
+	return sdk.SetUserFieldsByEmailAndProjectIdCtx(context.Background(), email, projectId, data)
+}
+
+func (sdk *SendiosSdk) SetUserFieldsByEmailAndProjectIdCtx(ctx context.Context, email string, projectId int, data map[string]string) ([]byte, error) {
 	encodedEmail := internal.Base64Encoder(email)
 
-	return sdk.Request.Put(ApiV1, fmt.Sprintf("userfields/project/%d/emailhash/%s", projectId, encodedEmail), data)
+	return sdk.do(ctx, "SetUserFieldsByEmailAndProjectId", http.MethodPut, sdk.apiV1Url(), internal.NewRoute("userfields/project/%d/emailhash/%s", projectId, encodedEmail), data, internal.WithProjectId(projectId))
 }
 
 func (sdk *SendiosSdk) SetUserFieldsByUserId(userId int, data map[string]string) ([]byte, error) {
 
-	return sdk.Request.Put(ApiV1, fmt.Sprintf("userfields/user/%d", userId), data)
+	return sdk.SetUserFieldsByUserIdCtx(context.Background(), userId, data)
+}
+
+func (sdk *SendiosSdk) SetUserFieldsByUserIdCtx(ctx context.Context, userId int, data map[string]string) ([]byte, error) {
+
+	return sdk.do(ctx, "SetUserFieldsByUserId", http.MethodPut, sdk.apiV1Url(), internal.NewRoute("userfields/user/%d", userId), data)
 }
 
 func (sdk *SendiosSdk) GetUserFieldsByEmailAndProjectId(email string, projectId int) ([]byte, error) {
 
-	return sdk.Request.Get(ApiV1, fmt.Sprintf("userfields/project/%d/email/%s", projectId, email))
+	return sdk.GetUserFieldsByEmailAndProjectIdCtx(context.Background(), email, projectId)
+}
+
+func (sdk *SendiosSdk) GetUserFieldsByEmailAndProjectIdCtx(ctx context.Context, email string, projectId int) ([]byte, error) {
+
+	return sdk.do(ctx, "GetUserFieldsByEmailAndProjectId", http.MethodGet, sdk.apiV1Url(), internal.NewRoute("userfields/project/%d/email/%s", projectId, email), nil, internal.WithProjectId(projectId))
 }
 
 func (sdk *SendiosSdk) GetUserFieldsByUserId(userId int) ([]byte, error) {
 
-	return sdk.Request.Get(ApiV1, fmt.Sprintf("userfields/user/%d", userId))
+	return sdk.GetUserFieldsByUserIdCtx(context.Background(), userId)
+}
+
+func (sdk *SendiosSdk) GetUserFieldsByUserIdCtx(ctx context.Context, userId int) ([]byte, error) {
+
+	return sdk.do(ctx, "GetUserFieldsByUserId", http.MethodGet, sdk.apiV1Url(), internal.NewRoute("userfields/user/%d", userId), nil)
 }
 
 func (sdk *SendiosSdk) SetOnlineByEmailAndProjectId(email string, projectId int) ([]byte, error) {
+
+	return sdk.SetOnlineByEmailAndProjectIdCtx(context.Background(), email, projectId)
+}
+
+func (sdk *SendiosSdk) SetOnlineByEmailAndProjectIdCtx(ctx context.Context, email string, projectId int) ([]byte, error) {
 	encodedEmail := internal.Base64Encoder(email)
 	params := internal.OnlineByProjectAndEmailUpdating{
 		ProjectId:    projectId,
@@ -247,28 +409,33 @@ func (sdk *SendiosSdk) SetOnlineByEmailAndProjectId(email string, projectId int)
 		Timestamp:    time.Now(),
 	}
 
-	return sdk.Request.Put(ApiV3, fmt.Sprintf("users/project/%d/email/%s/online", projectId, encodedEmail), params)
+	return sdk.do(ctx, "SetOnlineByEmailAndProjectId", http.MethodPut, sdk.apiV3Url(), internal.NewRoute("users/project/%d/email/%s/online", projectId, encodedEmail), params, internal.WithProjectId(projectId))
 }
 
 func (sdk *SendiosSdk) SetOnlineByUser(userId int) ([]byte, error) {
+
+	return sdk.SetOnlineByUserCtx(context.Background(), userId)
+}
+
+func (sdk *SendiosSdk) SetOnlineByUserCtx(ctx context.Context, userId int) ([]byte, error) {
 	params := internal.OnlineByUser{UserId: userId, Timestamp: time.Now()}
 
-	return sdk.Request.Put(ApiV3, fmt.Sprintf("users/%d/online", userId), params)
+	return sdk.do(ctx, "SetOnlineByUser", http.MethodPut, sdk.apiV3Url(), internal.NewRoute("users/%d/online", userId), params)
 }
 
 func (sdk *SendiosSdk) AddPaymentByEmailAndProjectId(email string, projectId int, startDate, expireDate int64, totalCount, paymentType, amount int) ([]byte, error) {
-	res, err := sdk.GetEmailUserByEmailAndProjectId(email, projectId)
-	if err != nil {
-		return nil, err
-	}
 
-	user, err := parseUserFromResponseData(res)
+	return sdk.AddPaymentByEmailAndProjectIdCtx(context.Background(), email, projectId, startDate, expireDate, totalCount, paymentType, amount)
+}
+
+func (sdk *SendiosSdk) AddPaymentByEmailAndProjectIdCtx(ctx context.Context, email string, projectId int, startDate, expireDate int64, totalCount, paymentType, amount int) ([]byte, error) {
+	userId, err := sdk.resolveUserId(ctx, email, projectId)
 	if err != nil {
-		return nil, fmt.Errorf("error while parsing push user: %s", err)
+		return nil, err
 	}
 
 	params := internal.Payment{
-		UserId:      user.Id,
+		UserId:      userId,
 		StartDate:   startDate,
 		ExpireDate:  expireDate,
 		TotalCount:  totalCount,
@@ -276,10 +443,15 @@ func (sdk *SendiosSdk) AddPaymentByEmailAndProjectId(email string, projectId int
 		Amount:      amount,
 	}
 
-	return sdk.Request.Post(ApiV1, "lastpayment", params)
+	return sdk.do(ctx, "AddPaymentByEmailAndProjectId", http.MethodPost, sdk.apiV1Url(), internal.NewRoute("lastpayment"), params, internal.WithProjectId(projectId))
 }
 
 func (sdk *SendiosSdk) AddPaymentByUserId(userId int, startDate, expireDate int64, totalCount, paymentType, amount int) ([]byte, error) {
+
+	return sdk.AddPaymentByUserIdCtx(context.Background(), userId, startDate, expireDate, totalCount, paymentType, amount)
+}
+
+func (sdk *SendiosSdk) AddPaymentByUserIdCtx(ctx context.Context, userId int, startDate, expireDate int64, totalCount, paymentType, amount int) ([]byte, error) {
 	params := internal.Payment{
 		UserId:      userId,
 		StartDate:   startDate,
@@ -289,10 +461,15 @@ func (sdk *SendiosSdk) AddPaymentByUserId(userId int, startDate, expireDate int6
 		Amount:      amount,
 	}
 
-	return sdk.Request.Post(ApiV1, "lastpayment", params)
+	return sdk.do(ctx, "AddPaymentByUserId", http.MethodPost, sdk.apiV1Url(), internal.NewRoute("lastpayment"), params)
 }
 
 func (sdk *SendiosSdk) ForceConfirmByEmailAndProject(email string, projectId int) ([]byte, error) {
+
+	return sdk.ForceConfirmByEmailAndProjectCtx(context.Background(), email, projectId)
+}
+
+func (sdk *SendiosSdk) ForceConfirmByEmailAndProjectCtx(ctx context.Context, email string, projectId int) ([]byte, error) {
 	encodedEmail := internal.Base64Encoder(email)
 
 	params := internal.ForceConfirm{
@@ -301,79 +478,84 @@ func (sdk *SendiosSdk) ForceConfirmByEmailAndProject(email string, projectId int
 		LastReaction: time.Now().Unix(),
 	}
 
-	return sdk.Request.Put(ApiV3, fmt.Sprintf("users/project/%d/email/%s/confirm", projectId, encodedEmail), params)
+	return sdk.do(ctx, "ForceConfirmByEmailAndProject", http.MethodPut, sdk.apiV3Url(), internal.NewRoute("users/project/%d/email/%s/confirm", projectId, encodedEmail), params, internal.WithProjectId(projectId))
 }
 
 func (sdk *SendiosSdk) UnsubscribePushUserByEmailUserId(userId int) ([]byte, error) {
-	res, err := sdk.GetPushUserById(userId)
-	if err != nil {
-		return nil, fmt.Errorf("error while getting push user: %s", err)
-	}
 
-	pushUser, err := parsePushUserFromResponseData(res)
+	return sdk.UnsubscribePushUserByEmailUserIdCtx(context.Background(), userId)
+}
+
+func (sdk *SendiosSdk) UnsubscribePushUserByEmailUserIdCtx(ctx context.Context, userId int) ([]byte, error) {
+	pushUser, err := sdk.resolvePushUserById(ctx, userId)
 	if err != nil {
-		return nil, fmt.Errorf("error while parsing push user: %s", err)
+		return nil, err
 	}
 
-	return sdk.Request.Post(ApiV1, fmt.Sprintf("webpush/unsubscribe/%d", pushUser.Id), nil)
+	return sdk.do(ctx, "UnsubscribePushUserByEmailUserId", http.MethodPost, sdk.apiV1Url(), internal.NewRoute("webpush/unsubscribe/%d", pushUser.Id), nil)
 }
 
 func (sdk *SendiosSdk) UnsubscribePushUserById(pushUserId int) ([]byte, error) {
 
-	return sdk.Request.Post(ApiV1, fmt.Sprintf("webpush/unsubscribe/%d", pushUserId), nil)
+	return sdk.UnsubscribePushUserByIdCtx(context.Background(), pushUserId)
+}
+
+func (sdk *SendiosSdk) UnsubscribePushUserByIdCtx(ctx context.Context, pushUserId int) ([]byte, error) {
+
+	return sdk.do(ctx, "UnsubscribePushUserById", http.MethodPost, sdk.apiV1Url(), internal.NewRoute("webpush/unsubscribe/%d", pushUserId), nil)
 }
 
 func (sdk *SendiosSdk) UnsubscribePushUserByProjectIdAndHash(projectId int, hash string) ([]byte, error) {
-	res, err := sdk.GetPushUserByProjectIdAndHash(projectId, hash)
-	if err != nil {
-		return nil, fmt.Errorf("error while getting push user: %s", err)
-	}
 
-	pushUser, err := parsePushUserFromResponseData(res)
+	return sdk.UnsubscribePushUserByProjectIdAndHashCtx(context.Background(), projectId, hash)
+}
+
+func (sdk *SendiosSdk) UnsubscribePushUserByProjectIdAndHashCtx(ctx context.Context, projectId int, hash string) ([]byte, error) {
+	pushUser, err := sdk.resolvePushUserByHash(ctx, projectId, hash)
 	if err != nil {
-		return nil, fmt.Errorf("error while parsing push user: %s", err)
+		return nil, err
 	}
 
-	return sdk.Request.Post(ApiV1, fmt.Sprintf("webpush/unsubscribe/%d", pushUser.Id), nil)
+	return sdk.do(ctx, "UnsubscribePushUserByProjectIdAndHash", http.MethodPost, sdk.apiV1Url(), internal.NewRoute("webpush/unsubscribe/%d", pushUser.Id), nil, internal.WithProjectId(projectId))
 }
 
 func (sdk *SendiosSdk) SubscribePushUserByEmailUserId(userId int) ([]byte, error) {
-	res, err := sdk.GetPushUserById(userId)
-	if err != nil {
-		return nil, fmt.Errorf("error while getting push user: %s", err)
-	}
 
-	pushUser, err := parsePushUserFromResponseData(res)
+	return sdk.SubscribePushUserByEmailUserIdCtx(context.Background(), userId)
+}
+
+func (sdk *SendiosSdk) SubscribePushUserByEmailUserIdCtx(ctx context.Context, userId int) ([]byte, error) {
+	pushUser, err := sdk.resolvePushUserById(ctx, userId)
 	if err != nil {
-		return nil, fmt.Errorf("error while parsing push user: %s", err)
+		return nil, err
 	}
 
-	return sdk.Request.Delete(ApiV1, fmt.Sprintf("webpush/subscribe/%d", pushUser.Id), nil)
+	return sdk.do(ctx, "SubscribePushUserByEmailUserId", http.MethodDelete, sdk.apiV1Url(), internal.NewRoute("webpush/subscribe/%d", pushUser.Id), nil)
 }
 
 func (sdk *SendiosSdk) SubscribePushUserByProjectIdAndHash(projectId int, hash string) ([]byte, error) {
-	res, err := sdk.GetPushUserByProjectIdAndHash(projectId, hash)
-	if err != nil {
-		return nil, fmt.Errorf("error while getting push user: %s", err)
-	}
 
-	pushUser, err := parsePushUserFromResponseData(res)
+	return sdk.SubscribePushUserByProjectIdAndHashCtx(context.Background(), projectId, hash)
+}
+
+func (sdk *SendiosSdk) SubscribePushUserByProjectIdAndHashCtx(ctx context.Context, projectId int, hash string) ([]byte, error) {
+	pushUser, err := sdk.resolvePushUserByHash(ctx, projectId, hash)
 	if err != nil {
-		return nil, fmt.Errorf("error while parsing push user: %s", err)
+		return nil, err
 	}
 
-	return sdk.Request.Delete(ApiV1, fmt.Sprintf("webpush/subscribe/%d", pushUser.Id), nil)
+	return sdk.do(ctx, "SubscribePushUserByProjectIdAndHash", http.MethodDelete, sdk.apiV1Url(), internal.NewRoute("webpush/subscribe/%d", pushUser.Id), nil, internal.WithProjectId(projectId))
 }
 
 func (sdk *SendiosSdk) SendPushByEmailUserId(userId int, title, text, url, iconUrl string, typeId int, meta map[string]string, imageUrl string) ([]byte, error) {
-	res, err := sdk.GetPushUserById(userId)
-	if err != nil {
-		return nil, fmt.Errorf("error while getting push user: %s", err)
-	}
 
-	pushUser, err := parsePushUserFromResponseData(res)
+	return sdk.SendPushByEmailUserIdCtx(context.Background(), userId, title, text, url, iconUrl, typeId, meta, imageUrl)
+}
+
+func (sdk *SendiosSdk) SendPushByEmailUserIdCtx(ctx context.Context, userId int, title, text, url, iconUrl string, typeId int, meta map[string]string, imageUrl string) ([]byte, error) {
+	pushUser, err := sdk.resolvePushUserById(ctx, userId)
 	if err != nil {
-		return nil, fmt.Errorf("error while parsing push user: %s", err)
+		return nil, err
 	}
 
 	params := internal.WebpushSend{
@@ -387,18 +569,18 @@ func (sdk *SendiosSdk) SendPushByEmailUserId(userId int, title, text, url, iconU
 		ImageUrl:   imageUrl,
 	}
 
-	return sdk.Request.Post(ApiV1, "webpush/send", params)
+	return sdk.do(ctx, "SendPushByEmailUserId", http.MethodPost, sdk.apiV1Url(), internal.NewRoute("webpush/send"), params)
 }
 
 func (sdk *SendiosSdk) SendPushByProjectIdAndHash(projectId int, hash, title, text, url, iconUrl string, typeId int, meta map[string]string, imageUrl string) ([]byte, error) {
-	res, err := sdk.GetPushUserByProjectIdAndHash(projectId, hash)
-	if err != nil {
-		return nil, fmt.Errorf("error while getting push user: %s", err)
-	}
 
-	pushUser, err := parsePushUserFromResponseData(res)
+	return sdk.SendPushByProjectIdAndHashCtx(context.Background(), projectId, hash, title, text, url, iconUrl, typeId, meta, imageUrl)
+}
+
+func (sdk *SendiosSdk) SendPushByProjectIdAndHashCtx(ctx context.Context, projectId int, hash, title, text, url, iconUrl string, typeId int, meta map[string]string, imageUrl string) ([]byte, error) {
+	pushUser, err := sdk.resolvePushUserByHash(ctx, projectId, hash)
 	if err != nil {
-		return nil, fmt.Errorf("error while parsing push user: %s", err)
+		return nil, err
 	}
 
 	params := internal.WebpushSend{
@@ -412,11 +594,16 @@ func (sdk *SendiosSdk) SendPushByProjectIdAndHash(projectId int, hash, title, te
 		Url:        url,
 	}
 
-	return sdk.Request.Post(ApiV1, "webpush/send", params)
+	return sdk.do(ctx, "SendPushByProjectIdAndHash", http.MethodPost, sdk.apiV1Url(), internal.NewRoute("webpush/send"), params, internal.WithProjectId(projectId))
 
 }
 
 func (sdk *SendiosSdk) SendPushByProject(projectId int, title, text, url, iconUrl string, typeId int, meta map[string]string, imageUrl string) ([]byte, error) {
+
+	return sdk.SendPushByProjectCtx(context.Background(), projectId, title, text, url, iconUrl, typeId, meta, imageUrl)
+}
+
+func (sdk *SendiosSdk) SendPushByProjectCtx(ctx context.Context, projectId int, title, text, url, iconUrl string, typeId int, meta map[string]string, imageUrl string) ([]byte, error) {
 	params := internal.WebpushSend{
 		Title:     title,
 		Text:      text,
@@ -428,62 +615,119 @@ func (sdk *SendiosSdk) SendPushByProject(projectId int, title, text, url, iconUr
 		Url:       url,
 	}
 
-	return sdk.Request.Post(ApiV1, "webpush/send", params)
+	return sdk.do(ctx, "SendPushByProject", http.MethodPost, sdk.apiV1Url(), internal.NewRoute("webpush/send"), params, internal.WithProjectId(projectId))
 }
 
 func (sdk *SendiosSdk) CreatePushUser(userId, projectId int, url, publicKey, authToken string) ([]byte, error) {
+
+	return sdk.CreatePushUserCtx(context.Background(), userId, projectId, url, publicKey, authToken)
+}
+
+func (sdk *SendiosSdk) CreatePushUserCtx(ctx context.Context, userId, projectId int, url, publicKey, authToken string) ([]byte, error) {
+	sdk.pushUsers.forget(userId, projectId)
 	meta := map[string]string{"url": url, "public_key": publicKey, "auth_token": authToken}
 	params := internal.WebpushUserCreate{
 		UserId: userId,
 		Meta:   meta,
 	}
 
-	return sdk.Request.Post(ApiV1, fmt.Sprintf("webpush/project/%d", projectId), params)
+	return sdk.do(ctx, "CreatePushUser", http.MethodPost, sdk.apiV1Url(), internal.NewRoute("webpush/project/%d", projectId), params, internal.WithProjectId(projectId))
 }
 
 func (sdk *SendiosSdk) GetPushUserById(userId int) ([]byte, error) {
 
-	return sdk.Request.Get(ApiV1, fmt.Sprintf("webpush/user/get/%d", userId))
+	return sdk.GetPushUserByIdCtx(context.Background(), userId)
+}
+
+func (sdk *SendiosSdk) GetPushUserByIdCtx(ctx context.Context, userId int) ([]byte, error) {
+
+	return sdk.do(ctx, "GetPushUserById", http.MethodGet, sdk.apiV1Url(), internal.NewRoute("webpush/user/get/%d", userId), nil)
 }
 
 func (sdk *SendiosSdk) GetPushUserByProjectIdAndHash(projectId int, hash string) ([]byte, error) {
 
-	return sdk.Request.Get(ApiV1, fmt.Sprintf("webpush/project/get/%d/hash/%s", projectId, hash))
+	return sdk.GetPushUserByProjectIdAndHashCtx(context.Background(), projectId, hash)
 }
 
-func (sdk *SendiosSdk) addEmailUserToUnsubList(userId int, sourceId int) ([]byte, error) {
+func (sdk *SendiosSdk) GetPushUserByProjectIdAndHashCtx(ctx context.Context, projectId int, hash string) ([]byte, error) {
 
-	return sdk.Request.Post(ApiV1, fmt.Sprintf("unsub/%d/source/%d", userId, sourceId), nil)
+	return sdk.do(ctx, "GetPushUserByProjectIdAndHash", http.MethodGet, sdk.apiV1Url(), internal.NewRoute("webpush/project/get/%d/hash/%s", projectId, hash), nil, internal.WithProjectId(projectId))
 }
 
-func getRoute(categoryId int) (string, error) {
-	elem, ok := m[categoryId]
+func (sdk *SendiosSdk) addEmailUserToUnsubList(ctx context.Context, operation string, userId int, sourceId int) ([]byte, error) {
+	// the cached email is needed to link the suppression, so it is read before the invalidation
+	known := sdk.knownUser(userId)
+	sdk.forgetUser(ctx, userId)
+	res, err := sdk.do(ctx, operation, http.MethodPost, sdk.apiV1Url(), internal.NewRoute("unsub/%d/source/%d", userId, sourceId), nil)
 
-	if ok != true {
-		return "", fmt.Errorf("not found route by category %d", categoryId)
+	return sdk.suppressAfter(ctx, res, err, func(list *SuppressionList) error {
+		return list.Add(Suppression{UserId: userId, All: true}, known)
+	})
+}
+
+// do sends a request and returns the raw json response, as the public api methods do.
+// The operation is the name of the public method, used to label metrics and spans.
+func (sdk *SendiosSdk) do(ctx context.Context, operation string, method string, baseUrl string, route internal.Route, body interface{}, opts ...internal.CallOption) ([]byte, error) {
+	opts = append(opts, internal.WithOperation(operation), internal.WithRouteTemplate(route.Template))
+
+	var res []byte
+	if err := sdk.Request.Do(ctx, method, baseUrl, route.Path, body, &res, opts...); err != nil {
+		return nil, err
 	}
 
-	return elem, nil
+	return res, nil
 }
 
-func parseUserFromResponseData(res []byte) (internal.EmailUser, error) {
-	var responseData *internal.ResponseEmailData
-	err := json.Unmarshal(res, &responseData)
+func (sdk *SendiosSdk) apiV1Url() string {
+	if sdk.apiV1 == "" {
+		return ApiV1
+	}
 
-	if err != nil {
-		return internal.EmailUser{}, fmt.Errorf("error while unmarshling email user data: %s", err)
+	return sdk.apiV1
+}
+
+func (sdk *SendiosSdk) apiV3Url() string {
+	if sdk.apiV3 == "" {
+		return ApiV3
 	}
 
-	return responseData.Data.User, nil
+	return sdk.apiV3
 }
 
-func parsePushUserFromResponseData(res []byte) (internal.PushUser, error) {
-	var responseData *internal.ResponsePushData
-	err := json.Unmarshal(res, &responseData)
+// encryptionKeyOrDefault falls back to the legacy key only when the sdk opted in, with a warning
+// logged once per sdk.
+func (sdk *SendiosSdk) encryptionKeyOrDefault(ctx context.Context) ([]byte, error) {
+	if len(sdk.encryptionKey) > 0 {
+		return sdk.encryptionKey, nil
+	}
+	if !sdk.legacyKey {
+		return nil, ErrNoEncryptionKey
+	}
+
+	sdk.legacyKeyOnce.Do(func() {
+		if sdk.Request.Logger != nil {
+			sdk.Request.Logger.Log(ctx, LogLevelWarn, "sendios template data is encrypted with the deprecated legacy key, configure one with WithEncryptionKey or "+EncryptionKeyEnv)
+		}
+	})
 
-	if err != nil {
-		return internal.PushUser{}, fmt.Errorf("error while unmarshling push user data: %s", err)
+	return []byte(legacyEncryptionKey), nil
+}
+
+func getRoute(categoryId int) (string, error) {
+	elem, ok := m[categoryId]
+
+	if ok != true {
+		return "", fmt.Errorf("not found route by category %d", categoryId)
+	}
+
+	return elem, nil
+}
+
+func parsePushUserFromResponseData(res []byte) (PushUser, error) {
+	var data pushUserData
+	if _, err := decodeEnvelope(res, &data); err != nil {
+		return PushUser{}, fmt.Errorf("error while unmarshling push user data: %w", err)
 	}
 
-	return responseData.Data.PushUser, nil
+	return data.PushUser, nil
 }
@@ -0,0 +1,277 @@
+package sendiostest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sendios/go-sdk/internal"
+)
+
+type RecorderMode int
+
+const (
+	// ModeReplay serves responses from the cassette and never touches the network.
+	ModeReplay RecorderMode = iota
+	// ModeRecord sends requests through Transport and stores them in the cassette on Stop.
+	ModeRecord
+)
+
+var ErrNoInteraction = errors.New("no recorded interaction matches the request")
+
+// DefaultIgnoredFields are request body fields the sdk fills with volatile values, such as
+// the current time or the randomly encrypted template data; they are left out when matching.
+var DefaultIgnoredFields = []string{"timestamp", "last_reaction", "template_data"}
+
+// Cassette is the file format of the recorder. Only json is supported, as the standard
+// library has no yaml encoder and the sdk does not pull in one for tests; cassettes are
+// indented so they still diff and review well.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	Route  string      `json:"route"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// Recorder is an http.RoundTripper that records the traffic of the sdk to a cassette file
+// and replays it later, e.g. in CI without network access:
+//
+//	recorder, err := sendiostest.NewRecorder("testdata/unsub.json", sendiostest.ModeReplay)
+//	sdk := sendios.NewSendiosSdk(clientId, authKey, sendios.WithTransport(recorder))
+//	defer recorder.Stop()
+//
+// Requests are matched on method, route and normalised json body. The Authorization header
+// is never stored and email addresses, plain or base64 encoded, are redacted in routes and bodies.
+type Recorder struct {
+	// Transport sends the requests in ModeRecord, http.DefaultTransport when nil.
+	Transport http.RoundTripper
+	// IgnoredFields are removed from json request bodies before matching.
+	IgnoredFields []string
+
+	mode     RecorderMode
+	path     string
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// NewRecorder reads the json cassette at path in ModeReplay. In ModeRecord the cassette is
+// written there on Stop, as json whatever the file extension.
+func NewRecorder(path string, mode RecorderMode) (*Recorder, error) {
+	r := &Recorder{IgnoredFields: DefaultIgnoredFields, mode: mode, path: path}
+
+	if mode == ModeRecord {
+		return r, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading cassette: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &r.cassette); err != nil {
+		return nil, fmt.Errorf("error while decoding cassette: %w", err)
+	}
+	r.used = make([]bool, len(r.cassette.Interactions))
+
+	return r, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading request body: %w", err)
+	}
+
+	recorded := RecordedRequest{
+		Method: req.Method,
+		Route:  internal.RedactRoute(req.URL.EscapedPath()),
+		Header: redactHeader(req.Header),
+		Body:   internal.RedactJSON(body),
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+
+	return r.record(req, body, recorded)
+}
+
+// Stop writes the cassette in ModeRecord and is a no-op in ModeReplay.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error while encoding cassette: %w", err)
+	}
+
+	return ioutil.WriteFile(r.path, append(data, '\n'), 0644)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte, recorded RecordedRequest) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	outgoing := req.Clone(req.Context())
+	outgoing.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	response, err := transport.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading response body: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: response.StatusCode,
+			Header:     redactHeader(response.Header),
+			Body:       internal.RedactJSON(responseBody),
+		},
+	})
+	r.mu.Unlock()
+
+	return newResponse(req, response.StatusCode, response.Header, responseBody), nil
+}
+
+// replay serves the first unused matching interaction, falling back to an already used one
+// so repeated identical calls keep working.
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	match := -1
+	for i, interaction := range r.cassette.Interactions {
+		if !r.matches(interaction.Request, recorded) {
+			continue
+		}
+		if !r.used[i] {
+			match = i
+			break
+		}
+		if match < 0 {
+			match = i
+		}
+	}
+
+	if match < 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, recorded.Method, recorded.Route)
+	}
+
+	r.used[match] = true
+	response := r.cassette.Interactions[match].Response
+
+	return newResponse(req, response.StatusCode, response.Header, []byte(response.Body)), nil
+}
+
+func (r *Recorder) matches(recorded RecordedRequest, incoming RecordedRequest) bool {
+	return recorded.Method == incoming.Method &&
+		recorded.Route == incoming.Route &&
+		r.normaliseBody(recorded.Body) == r.normaliseBody(incoming.Body)
+}
+
+// normaliseBody re-encodes json bodies with sorted keys and without the ignored fields.
+func (r *Recorder) normaliseBody(body string) string {
+	var value interface{}
+	if err := json.Unmarshal([]byte(body), &value); err != nil {
+		return strings.TrimSpace(body)
+	}
+
+	value = removeFields(value, r.IgnoredFields)
+	normalised, err := json.Marshal(value)
+	if err != nil {
+		return strings.TrimSpace(body)
+	}
+
+	return string(normalised)
+}
+
+func removeFields(value interface{}, fields []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, field := range fields {
+			delete(v, field)
+		}
+		for key, nested := range v {
+			v[key] = removeFields(nested, fields)
+		}
+	case []interface{}:
+		for i, nested := range v {
+			v[i] = removeFields(nested, fields)
+		}
+	}
+
+	return value
+}
+
+func redactHeader(header http.Header) http.Header {
+	redacted := http.Header{}
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		switch http.CanonicalHeaderKey(key) {
+		case "Authorization", "Cookie", "Set-Cookie":
+			redacted[key] = []string{internal.RedactedValue}
+		case "Date", "Content-Length", "User-Agent", "Accept-Encoding":
+		default:
+			for _, value := range header[key] {
+				redacted.Add(key, internal.RedactEmails(value))
+			}
+		}
+	}
+
+	return redacted
+}
+
+func newResponse(req *http.Request, statusCode int, header http.Header, body []byte) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,649 @@
+package sendiostest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const dateFormat = "2006-01-02 15:04:05"
+
+func (s *Server) registerRoutes() {
+	s.handle(http.MethodPost, "v1", "buying/email", s.buyingDecision)
+	s.handle(http.MethodPost, "v1", "clientuser/create", s.createClientUser)
+	s.handle(http.MethodPost, "v1", "email/check", s.checkEmail)
+	s.handle(http.MethodPost, "v1", "email/check/send", s.checkEmail)
+	s.handle(http.MethodPost, "v1", "trackemail/click/{mail}", s.trackClick)
+	s.handle(http.MethodPost, "v1", "product-event/create", s.productEvent)
+	s.handle(http.MethodPost, "v1", "push/system", s.sendEmail("push/system"))
+	s.handle(http.MethodPost, "v1", "push/trigger", s.sendEmail("push/trigger"))
+
+	s.handle(http.MethodGet, "v1", "unsubtypes/{user}", s.unsubTypes)
+	s.handle(http.MethodPost, "v1", "unsubtypes/{user}", s.setUnsubTypes)
+	s.handle(http.MethodPost, "v1", "unsubtypes/nodiff/{user}", s.addUnsubTypes)
+	s.handle(http.MethodDelete, "v1", "unsubtypes/nodiff/{user}", s.removeUnsubTypes)
+	s.handle(http.MethodDelete, "v1", "unsubtypes/all/{user}", s.removeAllUnsubTypes)
+
+	s.handle(http.MethodPost, "v1", "unsub/{user}/source/{source}", s.unsubscribe)
+	s.handle(http.MethodPost, "v1", "unsub/admin/{project}/email/{email}", s.unsubscribeByAdmin)
+	s.handle(http.MethodDelete, "v1", "unsub/{user}", s.subscribe)
+	s.handle(http.MethodGet, "v1", "unsub/isunsub/{user}", s.isUnsubscribed)
+	s.handle(http.MethodGet, "v1", "unsub/unsubreason/{user}", s.unsubscribeReason)
+	s.handle(http.MethodGet, "v1", "unsub/list/{time}", s.unsubscribes)
+
+	s.handle(http.MethodGet, "v1", "user/project/{project}/email/{email}", s.userByEmail)
+	s.handle(http.MethodGet, "v1", "user/id/{user}", s.userById)
+	s.handle(http.MethodPut, "v1", "userfields/project/{project}/emailhash/{email}", s.setUserFieldsByEmail)
+	s.handle(http.MethodPut, "v1", "userfields/user/{user}", s.setUserFieldsById)
+	s.handle(http.MethodGet, "v1", "userfields/project/{project}/email/{email}", s.userFieldsByEmail)
+	s.handle(http.MethodGet, "v1", "userfields/user/{user}", s.userFieldsById)
+	s.handle(http.MethodPost, "v1", "lastpayment", s.addPayment)
+
+	s.handle(http.MethodPost, "v1", "webpush/unsubscribe/{push}", s.setPushUnsubscribed(true))
+	s.handle(http.MethodDelete, "v1", "webpush/subscribe/{push}", s.setPushUnsubscribed(false))
+	s.handle(http.MethodPost, "v1", "webpush/send", s.sendPush)
+	s.handle(http.MethodPost, "v1", "webpush/project/{project}", s.createPushUser)
+	s.handle(http.MethodGet, "v1", "webpush/user/get/{user}", s.pushUserByUserId)
+	s.handle(http.MethodGet, "v1", "webpush/project/get/{project}/hash/{hash}", s.pushUserByHash)
+
+	s.handle(http.MethodPut, "v3", "users/project/{project}/email/{email}/online", s.setOnlineByEmail)
+	s.handle(http.MethodPut, "v3", "users/{user}/online", s.setOnlineById)
+	s.handle(http.MethodPut, "v3", "users/project/{project}/email/{email}/confirm", s.confirmByEmail)
+}
+
+func done() map[string]interface{} {
+	return map[string]interface{}{"date": time.Now().Format(dateFormat), "message": "done", "status": true}
+}
+
+func result(value interface{}) map[string]interface{} {
+	return map[string]interface{}{"result": value}
+}
+
+func notFound(format string, args ...interface{}) (int, interface{}) {
+	return http.StatusNotFound, map[string]string{"error": fmt.Sprintf(format, args...)}
+}
+
+func badRequest(err error) (int, interface{}) {
+	return http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Request validation error: %s", err)}
+}
+
+func (s *Server) buyingDecision(p params, body []byte) (int, interface{}) {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return badRequest(err)
+	}
+
+	return http.StatusOK, map[string]interface{}{"email": req.Email, "decision": false}
+}
+
+func (s *Server) createClientUser(p params, body []byte) (int, interface{}) {
+	var req struct {
+		Email     string `json:"email"`
+		ProjectId int    `json:"project_id"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return badRequest(err)
+	}
+
+	if s.findUser(req.ProjectId, req.Email) == nil {
+		s.addUser(User{Email: req.Email, ProjectId: req.ProjectId})
+	}
+
+	return http.StatusOK, done()
+}
+
+func (s *Server) checkEmail(p params, body []byte) (int, interface{}) {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return badRequest(err)
+	}
+
+	domain := ""
+	if at := strings.LastIndex(req.Email, "@"); at >= 0 {
+		domain = req.Email[at+1:]
+	}
+
+	check := map[string]interface{}{
+		"email":   req.Email,
+		"orig":    req.Email,
+		"domain":  domain,
+		"valid":   domain != "" && strings.Contains(domain, "."),
+		"trusted": domain != "",
+		"vendor":  "Unknown",
+	}
+	if domain == "" {
+		check["reason"] = "invalid"
+	}
+
+	return http.StatusOK, check
+}
+
+func (s *Server) trackClick(p params, body []byte) (int, interface{}) {
+	s.clicks = append(s.clicks, p.int(0))
+
+	return http.StatusOK, done()
+}
+
+func (s *Server) productEvent(p params, body []byte) (int, interface{}) {
+	s.events = append(s.events, append(json.RawMessage(nil), body...))
+
+	return http.StatusOK, done()
+}
+
+func (s *Server) sendEmail(route string) handler {
+	return func(p params, body []byte) (int, interface{}) {
+		var req struct {
+			TypeId       int               `json:"type_id"`
+			Category     int               `json:"category"`
+			ClientId     int               `json:"client_id"`
+			ProjectId    int               `json:"project_id"`
+			User         map[string]string `json:"user"`
+			Meta         map[string]string `json:"meta"`
+			ValueEncrypt struct {
+				TemplateData string `json:"template_data"`
+			} `json:"value_encrypt"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			return badRequest(err)
+		}
+
+		s.emails = append(s.emails, SentEmail{
+			Route:        route,
+			TypeId:       req.TypeId,
+			Category:     req.Category,
+			ClientId:     req.ClientId,
+			ProjectId:    req.ProjectId,
+			User:         req.User,
+			Meta:         req.Meta,
+			TemplateData: req.ValueEncrypt.TemplateData,
+		})
+
+		return http.StatusOK, nil
+	}
+}
+
+func (s *Server) unsubTypes(p params, body []byte) (int, interface{}) {
+	user, ok := s.users[p.int(0)]
+	if !ok {
+		return notFound("User not found")
+	}
+
+	types := make([]map[string]interface{}, 0, len(user.UnsubscribedTypes))
+	for _, typeId := range user.UnsubscribedTypes {
+		name, ok := s.UnsubTypeNames[typeId]
+		if !ok {
+			name = fmt.Sprintf("Type%d", typeId)
+		}
+		types = append(types, map[string]interface{}{"type_id": typeId, "name": name, "created_at": time.Now().Format(dateFormat)})
+	}
+
+	return http.StatusOK, types
+}
+
+func (s *Server) setUnsubTypes(p params, body []byte) (int, interface{}) {
+	return s.updateUnsubTypes(p, body, func(current map[int]bool, typeIds []int) map[int]bool {
+		next := map[int]bool{}
+		for _, typeId := range typeIds {
+			next[typeId] = true
+		}
+		return next
+	})
+}
+
+func (s *Server) addUnsubTypes(p params, body []byte) (int, interface{}) {
+	return s.updateUnsubTypes(p, body, func(current map[int]bool, typeIds []int) map[int]bool {
+		for _, typeId := range typeIds {
+			current[typeId] = true
+		}
+		return current
+	})
+}
+
+func (s *Server) removeUnsubTypes(p params, body []byte) (int, interface{}) {
+	return s.updateUnsubTypes(p, body, func(current map[int]bool, typeIds []int) map[int]bool {
+		for _, typeId := range typeIds {
+			delete(current, typeId)
+		}
+		return current
+	})
+}
+
+func (s *Server) removeAllUnsubTypes(p params, body []byte) (int, interface{}) {
+	return s.updateUnsubTypes(p, nil, func(current map[int]bool, typeIds []int) map[int]bool {
+		return map[int]bool{}
+	})
+}
+
+func (s *Server) updateUnsubTypes(p params, body []byte, update func(current map[int]bool, typeIds []int) map[int]bool) (int, interface{}) {
+	user, ok := s.users[p.int(0)]
+	if !ok {
+		return notFound("User not found")
+	}
+
+	var req struct {
+		TypeIds []int `json:"type_ids"`
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			return badRequest(err)
+		}
+	}
+
+	current := map[int]bool{}
+	for _, typeId := range user.UnsubscribedTypes {
+		current[typeId] = true
+	}
+
+	user.UnsubscribedTypes = nil
+	for typeId := range update(current, req.TypeIds) {
+		user.UnsubscribedTypes = append(user.UnsubscribedTypes, typeId)
+	}
+	sort.Ints(user.UnsubscribedTypes)
+
+	return http.StatusOK, nil
+}
+
+func (s *Server) unsubscribe(p params, body []byte) (int, interface{}) {
+	user, ok := s.users[p.int(0)]
+	if !ok {
+		return notFound("User not found")
+	}
+
+	s.unsubscribeUser(user, p.int(1))
+
+	return http.StatusOK, result(true)
+}
+
+func (s *Server) unsubscribeByAdmin(p params, body []byte) (int, interface{}) {
+	email := decodeEmail(p.string(1))
+
+	user := s.findUser(p.int(0), email)
+	if user == nil {
+		return notFound("Not found user for project %d and email %s", p.int(0), email)
+	}
+
+	s.unsubscribeUser(user, 0)
+
+	return http.StatusOK, result(true)
+}
+
+func (s *Server) subscribe(p params, body []byte) (int, interface{}) {
+	user, ok := s.users[p.int(0)]
+	if !ok {
+		return notFound("User not found")
+	}
+
+	rowCount := 0
+	if user.Unsubscribed {
+		rowCount = 1
+	}
+	user.Unsubscribed = false
+	user.UnsubSource = 0
+	user.UnsubscribedAt = time.Time{}
+
+	return http.StatusOK, map[string]interface{}{"subscribe": map[string]int{"rowCount": rowCount}}
+}
+
+func (s *Server) isUnsubscribed(p params, body []byte) (int, interface{}) {
+	user, ok := s.users[p.int(0)]
+	if !ok {
+		return notFound("User not found")
+	}
+
+	return http.StatusOK, result(user.Unsubscribed)
+}
+
+func (s *Server) unsubscribeReason(p params, body []byte) (int, interface{}) {
+	user, ok := s.users[p.int(0)]
+	if !ok {
+		return notFound("User not found")
+	}
+
+	if !user.Unsubscribed {
+		return http.StatusOK, result(false)
+	}
+
+	return http.StatusOK, result(map[string]interface{}{
+		"source_id": user.UnsubSource,
+		"date":      user.UnsubscribedAt.Format(dateFormat),
+	})
+}
+
+func (s *Server) unsubscribes(p params, body []byte) (int, interface{}) {
+	since := int64(p.int(0))
+
+	var users []*User
+	for _, user := range s.users {
+		if user.Unsubscribed && user.UnsubscribedAt.Unix() >= since {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].UnsubscribedAt.Equal(users[j].UnsubscribedAt) {
+			return users[i].Id < users[j].Id
+		}
+		return users[i].UnsubscribedAt.Before(users[j].UnsubscribedAt)
+	})
+
+	list := make([]map[string]interface{}, 0, len(users))
+	for _, user := range users {
+		list = append(list, map[string]interface{}{
+			"user_id":    user.Id,
+			"email":      user.Email,
+			"project_id": user.ProjectId,
+			"source_id":  user.UnsubSource,
+			"date":       user.UnsubscribedAt.Format(dateFormat),
+			"timestamp":  user.UnsubscribedAt.Unix(),
+		})
+	}
+
+	return http.StatusOK, list
+}
+
+func (s *Server) userByEmail(p params, body []byte) (int, interface{}) {
+	user := s.findUser(p.int(0), p.string(1))
+	if user == nil {
+		return notFound("Not found user for project %d and email %s", p.int(0), p.string(1))
+	}
+
+	return http.StatusOK, map[string]interface{}{"user": emailUserJSON(user)}
+}
+
+func (s *Server) userById(p params, body []byte) (int, interface{}) {
+	user, ok := s.users[p.int(0)]
+	if !ok {
+		return notFound("User not found")
+	}
+
+	return http.StatusOK, map[string]interface{}{"user": emailUserJSON(user)}
+}
+
+func (s *Server) setUserFieldsByEmail(p params, body []byte) (int, interface{}) {
+	email := decodeEmail(p.string(1))
+
+	user := s.findUser(p.int(0), email)
+	if user == nil {
+		return notFound("User not found.")
+	}
+
+	return setUserFields(user, body)
+}
+
+func (s *Server) setUserFieldsById(p params, body []byte) (int, interface{}) {
+	user, ok := s.users[p.int(0)]
+	if !ok {
+		return notFound("User not found.")
+	}
+
+	return setUserFields(user, body)
+}
+
+func setUserFields(user *User, body []byte) (int, interface{}) {
+	var fields map[string]string
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return badRequest(err)
+	}
+
+	if user.Fields == nil {
+		user.Fields = map[string]string{}
+	}
+	for key, value := range fields {
+		user.Fields[key] = value
+	}
+
+	return http.StatusOK, result(true)
+}
+
+func (s *Server) userFieldsByEmail(p params, body []byte) (int, interface{}) {
+	user := s.findUser(p.int(0), p.string(1))
+	if user == nil {
+		return notFound("User not found.")
+	}
+
+	return http.StatusOK, userFieldsJSON(user)
+}
+
+func (s *Server) userFieldsById(p params, body []byte) (int, interface{}) {
+	user, ok := s.users[p.int(0)]
+	if !ok {
+		return notFound("User not found.")
+	}
+
+	return http.StatusOK, userFieldsJSON(user)
+}
+
+func (s *Server) addPayment(p params, body []byte) (int, interface{}) {
+	var payment Payment
+	if err := json.Unmarshal(body, &payment); err != nil {
+		return badRequest(err)
+	}
+
+	if _, ok := s.users[payment.UserId]; !ok {
+		return notFound("User not found")
+	}
+
+	s.payments = append(s.payments, payment)
+
+	return http.StatusOK, done()
+}
+
+func (s *Server) setPushUnsubscribed(unsubscribed bool) handler {
+	return func(p params, body []byte) (int, interface{}) {
+		pushUser, ok := s.pushUsers[p.int(0)]
+		if !ok {
+			return notFound("Not found user by id: %d", p.int(0))
+		}
+
+		pushUser.Unsubscribed = unsubscribed
+
+		return http.StatusOK, result(true)
+	}
+}
+
+func (s *Server) sendPush(p params, body []byte) (int, interface{}) {
+	var req struct {
+		PushUserId int               `json:"push_user_id"`
+		Title      string            `json:"title"`
+		Url        string            `json:"url"`
+		Icon       string            `json:"icon"`
+		TypeId     int               `json:"type_id"`
+		Meta       map[string]string `json:"meta"`
+		Text       string            `json:"text"`
+		ImageUrl   string            `json:"image_url"`
+		ProjectId  int               `json:"project_id"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return badRequest(err)
+	}
+
+	if req.PushUserId != 0 {
+		if _, ok := s.pushUsers[req.PushUserId]; !ok {
+			return notFound("Not found user by id: %d", req.PushUserId)
+		}
+	}
+
+	s.pushes = append(s.pushes, SentPush{
+		PushUserId: req.PushUserId,
+		ProjectId:  req.ProjectId,
+		TypeId:     req.TypeId,
+		Title:      req.Title,
+		Text:       req.Text,
+		Url:        req.Url,
+		Icon:       req.Icon,
+		ImageUrl:   req.ImageUrl,
+		Meta:       req.Meta,
+	})
+
+	return http.StatusOK, result(true)
+}
+
+func (s *Server) createPushUser(p params, body []byte) (int, interface{}) {
+	var req struct {
+		UserId int               `json:"user_id"`
+		Meta   map[string]string `json:"meta"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return badRequest(err)
+	}
+
+	pushUser := &PushUser{Id: s.nextPushId, UserId: req.UserId, ProjectId: p.int(0), Hash: fmt.Sprintf("%x", s.nextPushId), Meta: req.Meta}
+	s.pushUsers[pushUser.Id] = pushUser
+	s.nextPushId++
+
+	return http.StatusOK, result(pushUserJSON(pushUser))
+}
+
+func (s *Server) pushUserByUserId(p params, body []byte) (int, interface{}) {
+	for _, pushUser := range s.sortedPushUsers() {
+		if pushUser.UserId == p.int(0) {
+			return http.StatusOK, result(pushUserJSON(pushUser))
+		}
+	}
+
+	return notFound("Not found user by id: %d", p.int(0))
+}
+
+func (s *Server) pushUserByHash(p params, body []byte) (int, interface{}) {
+	for _, pushUser := range s.sortedPushUsers() {
+		if pushUser.ProjectId == p.int(0) && pushUser.Hash == p.string(1) {
+			return http.StatusOK, result(pushUserJSON(pushUser))
+		}
+	}
+
+	return notFound("Not found user by hash: %s", p.string(1))
+}
+
+func (s *Server) setOnlineByEmail(p params, body []byte) (int, interface{}) {
+	if user := s.findUser(p.int(0), decodeEmail(p.string(1))); user != nil {
+		user.LastOnline = time.Now()
+	}
+
+	return http.StatusAccepted, accepted{Status: "Accepted"}
+}
+
+func (s *Server) setOnlineById(p params, body []byte) (int, interface{}) {
+	if user, ok := s.users[p.int(0)]; ok {
+		user.LastOnline = time.Now()
+	}
+
+	return http.StatusAccepted, accepted{Status: "Accepted"}
+}
+
+func (s *Server) confirmByEmail(p params, body []byte) (int, interface{}) {
+	if user := s.findUser(p.int(0), decodeEmail(p.string(1))); user != nil {
+		user.ConfirmedAt = time.Now()
+	}
+
+	return http.StatusAccepted, accepted{Status: "Accepted"}
+}
+
+func (s *Server) addUser(user User) *User {
+	if user.Id == 0 {
+		user.Id = s.nextUserId
+	}
+	if user.Id >= s.nextUserId {
+		s.nextUserId = user.Id + 1
+	}
+
+	stored := copyUser(&user)
+	s.users[stored.Id] = &stored
+
+	return &stored
+}
+
+func (s *Server) findUser(projectId int, email string) *User {
+	for _, user := range s.users {
+		if user.ProjectId == projectId && strings.EqualFold(user.Email, email) {
+			return user
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) unsubscribeUser(user *User, sourceId int) {
+	user.Unsubscribed = true
+	user.UnsubSource = sourceId
+	user.UnsubscribedAt = s.unsubscribeAt()
+}
+
+func (s *Server) sortedPushUsers() []*PushUser {
+	pushUsers := make([]*PushUser, 0, len(s.pushUsers))
+	for _, pushUser := range s.pushUsers {
+		pushUsers = append(pushUsers, pushUser)
+	}
+	sort.Slice(pushUsers, func(i, j int) bool { return pushUsers[i].Id < pushUsers[j].Id })
+
+	return pushUsers
+}
+
+func emailUserJSON(user *User) map[string]interface{} {
+	types := make([]map[string]interface{}, 0, len(user.UnsubscribedTypes))
+	for _, typeId := range user.UnsubscribedTypes {
+		types = append(types, map[string]interface{}{"type_id": typeId})
+	}
+
+	return map[string]interface{}{
+		"id":                user.Id,
+		"email":             user.Email,
+		"project_id":        user.ProjectId,
+		"name":              user.Name,
+		"unsubscribe_types": types,
+	}
+}
+
+func userFieldsJSON(user *User) map[string]interface{} {
+	var customFields interface{} = []interface{}{}
+	if len(user.Fields) > 0 {
+		customFields = user.Fields
+	}
+
+	return result(map[string]interface{}{
+		"custom_fields": customFields,
+		"user": map[string]interface{}{
+			"id":         user.Id,
+			"email":      user.Email,
+			"project_id": user.ProjectId,
+			"name":       user.Name,
+		},
+	})
+}
+
+func pushUserJSON(pushUser *PushUser) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         pushUser.Id,
+		"user_id":    pushUser.UserId,
+		"project_id": pushUser.ProjectId,
+		"hash":       pushUser.Hash,
+		"meta":       pushUser.Meta,
+	}
+}
+
+// decodeEmail accepts both the standard and the url-safe base64 alphabets.
+func decodeEmail(encoded string) string {
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if decoded, err := encoding.DecodeString(encoded); err == nil {
+			return string(decoded)
+		}
+	}
+
+	return encoded
+}
+
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+	defer r.Body.Close()
+
+	return ioutil.ReadAll(r.Body)
+}
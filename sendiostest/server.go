@@ -0,0 +1,415 @@
+// Package sendiostest provides an in-memory fake of the Sendios API for tests.
+//
+// The fake keeps users, unsubscribes, push users and everything sent through it, so tests
+// can exercise the real SendiosSdk methods and then assert on the resulting state:
+//
+//	server := sendiostest.NewServer()
+//	defer server.Close()
+//
+//	sdk := sendios.NewSendiosSdk("1", "key", server.Options()...)
+//	user := server.AddUser(sendiostest.User{Email: "test@gmail.com", ProjectId: 2})
+//	sdk.UnsubEmailUserClient(user.Id)
+//
+//	if !server.IsUnsubscribed(user.Id) { ... }
+package sendiostest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sendios "github.com/sendios/go-sdk"
+)
+
+type User struct {
+	Id        int
+	Email     string
+	ProjectId int
+	Name      string
+	Fields    map[string]string
+	// UnsubscribedTypes holds the email type ids the user opted out of.
+	UnsubscribedTypes []int
+	Unsubscribed      bool
+	UnsubSource       int
+	UnsubscribedAt    time.Time
+	LastOnline        time.Time
+	ConfirmedAt       time.Time
+}
+
+type PushUser struct {
+	Id           int
+	UserId       int
+	ProjectId    int
+	Hash         string
+	Meta         map[string]string
+	Unsubscribed bool
+}
+
+type SentEmail struct {
+	Route        string
+	TypeId       int
+	Category     int
+	ClientId     int
+	ProjectId    int
+	User         map[string]string
+	Meta         map[string]string
+	TemplateData string
+}
+
+type SentPush struct {
+	PushUserId int
+	ProjectId  int
+	TypeId     int
+	Title      string
+	Text       string
+	Url        string
+	Icon       string
+	ImageUrl   string
+	Meta       map[string]string
+}
+
+type Payment struct {
+	UserId      int   `json:"user_id"`
+	StartDate   int64 `json:"start_date"`
+	ExpireDate  int64 `json:"expire_date"`
+	TotalCount  int   `json:"total_count"`
+	PaymentType int   `json:"payment_type"`
+	Amount      int   `json:"amount"`
+}
+
+// Request is a request received by the fake, Route is relative to the api version.
+type Request struct {
+	Method  string
+	Version string
+	Route   string
+	Header  http.Header
+	Body    []byte
+}
+
+// Server is the fake api. All methods are safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	// UnsubTypeNames maps type ids to the names returned by the unsubtypes route.
+	UnsubTypeNames map[int]string
+
+	mu            sync.Mutex
+	routes        []route
+	users         map[int]*User
+	pushUsers     map[int]*PushUser
+	nextUserId    int
+	nextPushId    int
+	emails        []SentEmail
+	pushes        []SentPush
+	payments      []Payment
+	events        []json.RawMessage
+	clicks        []int
+	requests      []Request
+	failures      map[string]failure
+	unsubscribeAt func() time.Time
+}
+
+func NewServer() *Server {
+	s := &Server{
+		UnsubTypeNames: map[int]string{},
+		users:          map[int]*User{},
+		pushUsers:      map[int]*PushUser{},
+		failures:       map[string]failure{},
+		nextUserId:     1,
+		nextPushId:     1,
+		unsubscribeAt:  time.Now,
+	}
+	s.registerRoutes()
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// EncryptionKey is the key Options configures, so emails can be sent to the fake without one.
+const EncryptionKey = "0123456789abcdef"
+
+// Options points an sdk at the fake. A later WithEncryptionKey overrides EncryptionKey.
+func (s *Server) Options() []sendios.Option {
+	return []sendios.Option{
+		sendios.WithApiV1BaseUrl(s.URL + "/v1/"),
+		sendios.WithApiV3BaseUrl(s.URL + "/v3/"),
+		sendios.WithEncryptionKey(EncryptionKey),
+	}
+}
+
+// AddUser stores the user and returns it with its id assigned, unless one was set.
+func (s *Server) AddUser(user User) User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := s.addUser(user)
+
+	return copyUser(stored)
+}
+
+func (s *Server) AddPushUser(pushUser PushUser) PushUser {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if pushUser.Id == 0 {
+		pushUser.Id = s.nextPushId
+	}
+	if pushUser.Id >= s.nextPushId {
+		s.nextPushId = pushUser.Id + 1
+	}
+
+	stored := pushUser
+	s.pushUsers[stored.Id] = &stored
+
+	return stored
+}
+
+func (s *Server) User(id int) (User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return User{}, false
+	}
+
+	return copyUser(user), true
+}
+
+func (s *Server) PushUser(id int) (PushUser, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pushUser, ok := s.pushUsers[id]
+	if !ok {
+		return PushUser{}, false
+	}
+
+	return *pushUser, true
+}
+
+func (s *Server) IsUnsubscribed(userId int) bool {
+	user, ok := s.User(userId)
+
+	return ok && user.Unsubscribed
+}
+
+func (s *Server) SentEmails() []SentEmail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]SentEmail(nil), s.emails...)
+}
+
+func (s *Server) SentPushes() []SentPush {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]SentPush(nil), s.pushes...)
+}
+
+func (s *Server) Payments() []Payment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Payment(nil), s.payments...)
+}
+
+func (s *Server) ProductEvents() []json.RawMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]json.RawMessage(nil), s.events...)
+}
+
+func (s *Server) Clicks() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]int(nil), s.clicks...)
+}
+
+// Requests returns every request received, in arrival order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// FailNext makes the next count requests to the route template, e.g. "GET user/id/{user}",
+// fail with the given status code.
+func (s *Server) FailNext(routeTemplate string, count int, statusCode int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures[routeTemplate] = failure{count: count, statusCode: statusCode}
+}
+
+// SetClock replaces the clock used to time unsubscribes, for tests of GetUnsubscribesByDate.
+func (s *Server) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.unsubscribeAt = now
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := readBody(r)
+	version, segments := splitPath(r.URL.EscapedPath())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, Request{
+		Method:  r.Method,
+		Version: version,
+		Route:   strings.Join(segments, "/"),
+		Header:  r.Header.Clone(),
+		Body:    body,
+	})
+
+	if _, _, ok := r.BasicAuth(); !ok {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	for _, rt := range s.routes {
+		params, ok := rt.match(r.Method, version, segments)
+		if !ok {
+			continue
+		}
+
+		if f := s.failures[rt.name]; f.count > 0 {
+			s.failures[rt.name] = failure{count: f.count - 1, statusCode: f.statusCode}
+			writeError(w, f.statusCode, "Injected failure")
+			return
+		}
+
+		status, data := rt.handler(params, body)
+		writeData(w, status, data)
+		return
+	}
+
+	writeError(w, http.StatusNotFound, fmt.Sprintf("Route %s %s not found", r.Method, r.URL.Path))
+}
+
+type failure struct {
+	count      int
+	statusCode int
+}
+
+type params []string
+
+func (p params) int(i int) int {
+	value, _ := strconv.Atoi(p[i])
+
+	return value
+}
+
+func (p params) string(i int) string {
+	return p[i]
+}
+
+type handler func(p params, body []byte) (int, interface{})
+
+type route struct {
+	name     string
+	method   string
+	version  string
+	segments []string
+	handler  handler
+}
+
+func (rt route) match(method string, version string, segments []string) (params, bool) {
+	if method != rt.method || version != rt.version || len(segments) != len(rt.segments) {
+		return nil, false
+	}
+
+	var p params
+	for i, segment := range rt.segments {
+		if strings.HasPrefix(segment, "{") {
+			p = append(p, segments[i])
+			continue
+		}
+		if segment != segments[i] {
+			return nil, false
+		}
+	}
+
+	return p, true
+}
+
+func (s *Server) handle(method string, version string, template string, h handler) {
+	s.routes = append(s.routes, route{
+		name:     method + " " + template,
+		method:   method,
+		version:  version,
+		segments: strings.Split(template, "/"),
+		handler:  h,
+	})
+}
+
+// splitPath returns the api version and the unescaped route segments.
+func splitPath(escapedPath string) (string, []string) {
+	parts := strings.Split(strings.Trim(escapedPath, "/"), "/")
+	for i, part := range parts {
+		if unescaped, err := url.PathUnescape(part); err == nil {
+			parts[i] = unescaped
+		}
+	}
+
+	if len(parts) == 0 {
+		return "", nil
+	}
+
+	return parts[0], parts[1:]
+}
+
+type envelope struct {
+	Meta sendios.Meta `json:"_meta"`
+	Data interface{}  `json:"data"`
+}
+
+// accepted is returned as is by the v3 routes, which do not use the envelope.
+type accepted struct {
+	Status string `json:"status"`
+}
+
+func writeData(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+
+	if a, ok := data.(accepted); ok {
+		_ = json.NewEncoder(w).Encode(a)
+		return
+	}
+
+	metaStatus := sendios.MetaStatusSuccess
+	if status >= http.StatusBadRequest {
+		metaStatus = sendios.MetaStatusError
+	}
+
+	_ = json.NewEncoder(w).Encode(envelope{Meta: sendios.Meta{Count: 1, Status: metaStatus}, Data: data})
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeData(w, status, map[string]string{"error": message})
+}
+
+func copyUser(user *User) User {
+	copied := *user
+	copied.Fields = map[string]string{}
+	for key, value := range user.Fields {
+		copied.Fields[key] = value
+	}
+	copied.UnsubscribedTypes = append([]int(nil), user.UnsubscribedTypes...)
+
+	return copied
+}
@@ -0,0 +1,406 @@
+package go_sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sendios/go-sdk/internal"
+)
+
+// ErrSuppressed is returned by SendEmail and Send, when the suppression check is enabled with
+// WithSuppressionCheck, for an email user who unsubscribed from the email type.
+var ErrSuppressed = errors.New("email user is unsubscribed")
+
+// Suppression is what the suppression list knows about an email user: All is set when the user
+// unsubscribed from every email, TypeIds holds the email types the user unsubscribed from.
+// The user is known by id, by project and email or by both.
+type Suppression struct {
+	UserId    int    `json:"user_id,omitempty"`
+	ProjectId int    `json:"project_id,omitempty"`
+	Email     string `json:"email,omitempty"`
+	All       bool   `json:"all,omitempty"`
+	TypeIds   []int  `json:"type_ids,omitempty"`
+}
+
+// Suppresses reports whether an email of the type must not be sent to the user.
+func (s Suppression) Suppresses(typeId int) bool {
+	if s.All {
+		return true
+	}
+	for _, id := range s.TypeIds {
+		if id == typeId {
+			return true
+		}
+	}
+
+	return false
+}
+
+type suppressionSnapshot struct {
+	Suppressions []Suppression `json:"suppressions"`
+}
+
+// SuppressionList keeps the unsubscribed email users in memory, so sending can skip them
+// without asking the api. Given to the sdk with WithSuppressionList, it is updated by the
+// subscription methods of the sdk and by UnsubFeed. With a path, the list is loaded from the
+// file and the file is replaced by a snapshot after every change.
+type SuppressionList struct {
+	path    string
+	mu      sync.RWMutex
+	byUser  map[int]*Suppression
+	byEmail map[string]*Suppression
+}
+
+// NewSuppressionList loads the snapshot at path, if any. An empty path keeps the list in memory only.
+func NewSuppressionList(path string) (*SuppressionList, error) {
+	l := &SuppressionList{path: path, byUser: map[int]*Suppression{}, byEmail: map[string]*Suppression{}}
+	if path == "" {
+		return l, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while reading suppression list: %w", err)
+	}
+
+	var snapshot suppressionSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("error while decoding suppression list: %w", err)
+	}
+	for _, suppression := range snapshot.Suppressions {
+		l.merge(suppression)
+	}
+
+	return l, nil
+}
+
+// Get returns the suppression of the user with the email in the project.
+func (l *SuppressionList) Get(projectId int, email string) (Suppression, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return copySuppression(l.byEmail[suppressionKey(projectId, email)])
+}
+
+func (l *SuppressionList) GetUser(userId int) (Suppression, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return copySuppression(l.byUser[userId])
+}
+
+// Suppressions returns a copy of every suppression, ordered by user id, project and email.
+func (l *SuppressionList) Suppressions() []Suppression {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.list()
+}
+
+func (l *SuppressionList) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return len(l.list())
+}
+
+// Add merges every suppression into the list and saves it once: the flags and type ids are
+// added to the ones already known for the user, and the id and the email of the user are
+// linked when a suppression has both. A suppression without All and type ids only links the
+// user to an existing entry.
+func (l *SuppressionList) Add(suppressions ...Suppression) error {
+	changes := make([]func(), 0, len(suppressions))
+	for _, s := range suppressions {
+		changes = append(changes, l.add(s))
+	}
+
+	return l.update(changes...)
+}
+
+// SetTypes replaces the email types the user is unsubscribed from.
+func (l *SuppressionList) SetTypes(userId int, typeIds []int) error {
+	return l.update(l.setTypes(userId, typeIds))
+}
+
+// RemoveTypes resubscribes the user to the email types, nothing is changed when typeIds is empty.
+func (l *SuppressionList) RemoveTypes(userId int, typeIds []int) error {
+	return l.update(l.removeTypes(userId, typeIds))
+}
+
+// RemoveAllTypes resubscribes the user to every email type, All is kept.
+func (l *SuppressionList) RemoveAllTypes(userId int) error {
+	return l.update(l.removeAllTypes(userId))
+}
+
+// Resubscribe clears All for the user, the email types the user unsubscribed from are kept.
+func (l *SuppressionList) Resubscribe(userId int) error {
+	return l.update(l.resubscribe(userId))
+}
+
+// update applies the changes in order and saves the list once.
+func (l *SuppressionList) update(changes ...func()) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, change := range changes {
+		change()
+	}
+
+	return l.save()
+}
+
+func (l *SuppressionList) add(s Suppression) func() {
+	return func() {
+		l.merge(s)
+	}
+}
+
+func (l *SuppressionList) setTypes(userId int, typeIds []int) func() {
+	return func() {
+		entry := l.byUser[userId]
+		if entry == nil {
+			l.merge(Suppression{UserId: userId, TypeIds: typeIds})
+			return
+		}
+		entry.TypeIds = uniqueTypeIds(nil, typeIds)
+		l.prune(entry)
+	}
+}
+
+func (l *SuppressionList) removeTypes(userId int, typeIds []int) func() {
+	return func() {
+		entry := l.byUser[userId]
+		if entry == nil {
+			return
+		}
+
+		for _, typeId := range typeIds {
+			for i, id := range entry.TypeIds {
+				if id == typeId {
+					entry.TypeIds = append(entry.TypeIds[:i], entry.TypeIds[i+1:]...)
+					break
+				}
+			}
+		}
+		l.prune(entry)
+	}
+}
+
+func (l *SuppressionList) removeAllTypes(userId int) func() {
+	return func() {
+		if entry := l.byUser[userId]; entry != nil {
+			entry.TypeIds = nil
+			l.prune(entry)
+		}
+	}
+}
+
+func (l *SuppressionList) resubscribe(userId int) func() {
+	return func() {
+		if entry := l.byUser[userId]; entry != nil {
+			entry.All = false
+			l.prune(entry)
+		}
+	}
+}
+
+func (l *SuppressionList) merge(s Suppression) {
+	var byUser, byEmail *Suppression
+	if s.UserId != 0 {
+		byUser = l.byUser[s.UserId]
+	}
+	if s.Email != "" {
+		byEmail = l.byEmail[suppressionKey(s.ProjectId, s.Email)]
+	}
+
+	entry := byUser
+	if entry == nil {
+		entry = byEmail
+	}
+	if entry == nil {
+		if !s.All && len(s.TypeIds) == 0 {
+			return
+		}
+		entry = &Suppression{}
+	}
+	if byUser != nil && byEmail != nil && byUser != byEmail {
+		entry.All = entry.All || byEmail.All
+		entry.TypeIds = uniqueTypeIds(entry.TypeIds, byEmail.TypeIds)
+		if byEmail.UserId != 0 && byEmail.UserId != entry.UserId {
+			delete(l.byUser, byEmail.UserId)
+		}
+	}
+
+	if s.UserId != 0 {
+		entry.UserId = s.UserId
+	}
+	if s.Email != "" {
+		if entry.Email != "" {
+			delete(l.byEmail, suppressionKey(entry.ProjectId, entry.Email))
+		}
+		entry.ProjectId = s.ProjectId
+		entry.Email = s.Email
+	}
+	entry.All = entry.All || s.All
+	entry.TypeIds = uniqueTypeIds(entry.TypeIds, s.TypeIds)
+
+	if entry.UserId != 0 {
+		l.byUser[entry.UserId] = entry
+	}
+	if entry.Email != "" {
+		l.byEmail[suppressionKey(entry.ProjectId, entry.Email)] = entry
+	}
+}
+
+// prune drops the entry once the user is no longer unsubscribed from anything.
+func (l *SuppressionList) prune(entry *Suppression) {
+	if entry.All || len(entry.TypeIds) > 0 {
+		return
+	}
+
+	if l.byUser[entry.UserId] == entry {
+		delete(l.byUser, entry.UserId)
+	}
+	key := suppressionKey(entry.ProjectId, entry.Email)
+	if l.byEmail[key] == entry {
+		delete(l.byEmail, key)
+	}
+}
+
+func (l *SuppressionList) list() []Suppression {
+	seen := map[*Suppression]bool{}
+	list := []Suppression{}
+	add := func(entry *Suppression) {
+		if !seen[entry] {
+			seen[entry] = true
+			suppression, _ := copySuppression(entry)
+			list = append(list, suppression)
+		}
+	}
+	for _, entry := range l.byUser {
+		add(entry)
+	}
+	for _, entry := range l.byEmail {
+		add(entry)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].UserId != list[j].UserId {
+			return list[i].UserId < list[j].UserId
+		}
+		if list[i].ProjectId != list[j].ProjectId {
+			return list[i].ProjectId < list[j].ProjectId
+		}
+		return list[i].Email < list[j].Email
+	})
+
+	return list
+}
+
+func (l *SuppressionList) save() error {
+	if l.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(suppressionSnapshot{Suppressions: l.list()})
+	if err != nil {
+		return fmt.Errorf("error while encoding suppression list: %w", err)
+	}
+
+	return internal.WriteFileAtomic(l.path, data)
+}
+
+func suppressionKey(projectId int, email string) string {
+	return fmt.Sprintf("%d/%s", projectId, strings.ToLower(strings.TrimSpace(email)))
+}
+
+func copySuppression(entry *Suppression) (Suppression, bool) {
+	if entry == nil {
+		return Suppression{}, false
+	}
+
+	suppression := *entry
+	suppression.TypeIds = append([]int(nil), entry.TypeIds...)
+
+	return suppression, true
+}
+
+func uniqueTypeIds(typeIds []int, add []int) []int {
+	for _, typeId := range add {
+		known := false
+		for _, id := range typeIds {
+			if id == typeId {
+				known = true
+				break
+			}
+		}
+		if !known {
+			typeIds = append(typeIds, typeId)
+		}
+	}
+	sort.Ints(typeIds)
+
+	return typeIds
+}
+
+// checkSuppressed returns ErrSuppressed when the check is enabled and the user unsubscribed
+// from the type. Users only known by id are matched through the user id cache, never the api.
+func (sdk *SendiosSdk) checkSuppressed(ctx context.Context, projectId int, email string, typeId int) error {
+	if !sdk.checkSuppressions || sdk.suppressions == nil {
+		return nil
+	}
+
+	suppression, ok := sdk.suppressions.Get(projectId, email)
+	if !ok && sdk.userIds != nil {
+		if userId, cached := sdk.userIds.Get(ctx, projectId, email); cached {
+			suppression, ok = sdk.suppressions.GetUser(userId)
+		}
+	}
+	if ok && suppression.Suppresses(typeId) {
+		return fmt.Errorf("%w: project %d, type %d", ErrSuppressed, projectId, typeId)
+	}
+
+	return nil
+}
+
+// suppressAfter applies change to the suppression list once the call which changed the
+// subscription of the user succeeded. The change already happened in the api, so a failure to
+// save the list does not fail the call, it is logged instead.
+func (sdk *SendiosSdk) suppressAfter(ctx context.Context, res []byte, err error, change func(list *SuppressionList) error) ([]byte, error) {
+	if err != nil || sdk.suppressions == nil {
+		return res, err
+	}
+
+	if err := change(sdk.suppressions); err != nil && sdk.Request.Logger != nil {
+		sdk.Request.Logger.Log(ctx, LogLevelError, "sendios suppression list update failed", "error", err)
+	}
+
+	return res, nil
+}
+
+// userEmailCache is implemented by the default UserIdCache, which can tell the email of a
+// cached user id.
+type userEmailCache interface {
+	email(userId int) (projectId int, email string, ok bool)
+}
+
+// knownUser returns a suppression linking the user to the email cached for it, if any, so sends
+// to the email are matched. The unsubscribe feed links the other users.
+func (sdk *SendiosSdk) knownUser(userId int) Suppression {
+	suppression := Suppression{UserId: userId}
+	if cache, ok := sdk.userIds.(userEmailCache); ok {
+		suppression.ProjectId, suppression.Email, _ = cache.email(userId)
+	}
+
+	return suppression
+}
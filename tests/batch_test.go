@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sendios "github.com/sendios/go-sdk"
+	"github.com/sendios/go-sdk/internal"
+)
+
+func TestSendiosSdk_SendEmails(t *testing.T) {
+	var inFlight, maxInFlight int32
+	var mu sync.Mutex
+	attempts := map[string]int{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		var sent internal.EmailSend
+		_ = json.NewDecoder(r.Body).Decode(&sent)
+		email := sent.User["email"]
+
+		mu.Lock()
+		attempts[email]++
+		attempt := attempts[email]
+		mu.Unlock()
+
+		switch {
+		case email == "invalid@gmail.com":
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"_meta":{"status":"ERROR"},"data":{"error":"invalid"}}`)
+		case email == "flaky@gmail.com" && attempt == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			fmt.Fprint(w, `{"_meta":{"count":1,"status":"SUCCESS","time":4275},"data":null}`)
+		}
+	}))
+	defer ts.Close()
+
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6",
+		sendios.WithApiV1BaseUrl(ts.URL),
+		sendios.WithEncryptionKey("0123456789abcdef"),
+		sendios.WithRetryPolicy(sendios.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, RetryableStatuses: []int{http.StatusServiceUnavailable}}),
+	)
+
+	var msgs []sendios.EmailMessage
+	for i := 0; i < 20; i++ {
+		msgs = append(msgs, sendios.NewEmailMessage(fmt.Sprintf("user%d@gmail.com", i)).WithProject(2).WithType(sendios.Trigger, 1))
+	}
+	msgs = append(msgs,
+		sendios.NewEmailMessage("invalid@gmail.com").WithProject(2).WithType(sendios.Trigger, 1),
+		sendios.NewEmailMessage("flaky@gmail.com").WithProject(2).WithType(sendios.Trigger, 1),
+		sendios.NewEmailMessage("").WithProject(2).WithType(sendios.Trigger, 1),
+	)
+
+	report := sdk.SendEmails(context.Background(), msgs, sendios.BatchOptions{
+		Concurrency: 3,
+		IdempotencyKey: func(index int, msg sendios.EmailMessage) string {
+			return "batch-" + strconv.Itoa(index)
+		},
+	})
+
+	if len(report.Results) != len(msgs) {
+		t.Fatalf("results = %v, want %v", len(report.Results), len(msgs))
+	}
+	if report.Succeeded != 21 || report.Failed != 2 {
+		t.Errorf("succeeded = %v, failed = %v, want 21 and 2", report.Succeeded, report.Failed)
+	}
+	if maxInFlight > 3 {
+		t.Errorf("max in flight = %v, want at most 3", maxInFlight)
+	}
+	for i, result := range report.Results {
+		if result.Index != i || result.Message.Email != msgs[i].Email {
+			t.Errorf("result %d is out of order: %+v", i, result)
+		}
+	}
+
+	failures := report.Failures()
+	if len(failures) != 2 {
+		t.Fatalf("failures = %v, want 2", len(failures))
+	}
+	if failures[0].Message.Email != "invalid@gmail.com" {
+		t.Errorf("unexpected failure %+v", failures[0])
+	}
+	var apiError *sendios.APIError
+	if !errors.As(failures[0].Err, &apiError) || apiError.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected bad request APIError, got %v", failures[0].Err)
+	}
+	if !errors.Is(failures[1].Err, sendios.ErrInvalidEmailMessage) {
+		t.Errorf("expected ErrInvalidEmailMessage, got %v", failures[1].Err)
+	}
+	if attempts["flaky@gmail.com"] != 2 {
+		t.Errorf("flaky message attempts = %v, want 2", attempts["flaky@gmail.com"])
+	}
+}
+
+func TestSendiosSdk_SendEmailsCancelled(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		fmt.Fprint(w, `{"_meta":{"status":"SUCCESS"},"data":null}`)
+	}))
+	defer ts.Close()
+
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", sendios.WithApiV1BaseUrl(ts.URL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	msgs := []sendios.EmailMessage{
+		sendios.NewEmailMessage("a@gmail.com").WithProject(2).WithType(sendios.Trigger, 1),
+		sendios.NewEmailMessage("b@gmail.com").WithProject(2).WithType(sendios.Trigger, 1),
+	}
+
+	report := sdk.SendEmails(ctx, msgs, sendios.BatchOptions{})
+	if report.Failed != 2 || requests != 0 {
+		t.Errorf("failed = %v, requests = %v, want 2 and 0", report.Failed, requests)
+	}
+	for _, result := range report.Results {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("expected context canceled, got %v", result.Err)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	sendios "github.com/sendios/go-sdk"
+	"github.com/sendios/go-sdk/internal"
+)
+
+func TestEmailMessage_Validate(t *testing.T) {
+	valid := sendios.NewEmailMessage("test@gmail.com").WithProject(2).WithType(sendios.Trigger, 1)
+
+	tests := []struct {
+		name    string
+		msg     sendios.EmailMessage
+		wantErr bool
+	}{
+		{"valid", valid, false},
+		{"system_category", valid.WithType(sendios.System, 1), false},
+		{"unknown_category", valid.WithType(5, 1), true},
+		{"with_client", valid.WithClient(3), false},
+		{"blank_email", sendios.EmailMessage{Email: "  ", ProjectId: 2, Category: sendios.Trigger}, true},
+		{"zero_project", valid.WithProject(0), true},
+		{"negative_project", valid.WithProject(-1), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.msg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, sendios.ErrInvalidEmailMessage) {
+				t.Errorf("Validate() error = %v, want ErrInvalidEmailMessage", err)
+			}
+		})
+	}
+}
+
+func TestSendiosSdk_Send(t *testing.T) {
+	type templateData struct {
+		Name  string `json:"name"`
+		Items []int  `json:"items"`
+	}
+
+	tests := []struct {
+		name         string
+		msg          sendios.EmailMessage
+		wantPath     string
+		wantTemplate string
+		wantErr      bool
+	}{
+		{"trigger_with_struct_data",
+			sendios.NewEmailMessage("test@gmail.com").WithClient(3).WithProject(2).WithType(sendios.Trigger, 15).
+				WithUser(map[string]string{"id": "1"}).WithMeta(map[string]string{"meta": "email"}).
+				WithData(templateData{Name: "Volodymyr", Items: []int{1, 2}}),
+			"/push/trigger", `{"name":"Volodymyr","items":[1,2]}`, false},
+		{"system_with_map_data",
+			sendios.NewEmailMessage("test@gmail.com").WithProject(2).WithType(sendios.System, 1).WithData(map[string]string{"data": "test"}),
+			"/push/system", `{"data":"test"}`, false},
+		{"invalid_message_is_not_sent",
+			sendios.NewEmailMessage("").WithProject(2).WithType(sendios.System, 1),
+			"", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+			var sent internal.EmailSend
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				_ = json.NewDecoder(r.Body).Decode(&sent)
+				fmt.Fprint(w, `{"_meta":{"count":1,"status":"SUCCESS","time":4275},"data":null}`)
+			}))
+			defer ts.Close()
+
+			sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", sendios.WithApiV1BaseUrl(ts.URL), sendios.WithEncryptionKey("0123456789abcdef"))
+
+			var userBefore map[string]string
+			if tt.msg.User != nil {
+				userBefore = map[string]string{}
+				for k, v := range tt.msg.User {
+					userBefore[k] = v
+				}
+			}
+
+			_, err := sdk.Send(context.Background(), tt.msg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.msg.User, userBefore) {
+				t.Errorf("Send() mutated user map: %v", tt.msg.User)
+			}
+			if tt.wantErr {
+				if gotPath != "" {
+					t.Errorf("invalid message was sent to %v", gotPath)
+				}
+				return
+			}
+
+			if gotPath != tt.wantPath {
+				t.Errorf("path = %v, want %v", gotPath, tt.wantPath)
+			}
+			if sent.User["email"] != tt.msg.Email || sent.ProjectId != tt.msg.ProjectId || sent.TypeId != tt.msg.TypeId || sent.ClientId != tt.msg.ClientId {
+				t.Errorf("unexpected payload %+v", sent)
+			}
+
+			encrypt, _ := internal.MakeEncrypt([]byte("0123456789abcdef"))
+			template, err := encrypt.Decrypt(sent.ValueEncrypt.TemplateData)
+			if err != nil {
+				t.Fatalf("Decrypt() error = %v", err)
+			}
+			if template != tt.wantTemplate {
+				t.Errorf("template data = %v, want %v", template, tt.wantTemplate)
+			}
+		})
+	}
+}
@@ -1,7 +1,7 @@
 package tests
 
 import (
-	"sendios/internal"
+	"github.com/sendios/go-sdk/internal"
 	"testing"
 )
 
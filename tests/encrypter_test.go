@@ -1,33 +1,53 @@
 package tests
 
 import (
-	"crypto/cipher"
-	"sendios/internal"
+	"encoding/base64"
 	"testing"
+
+	"github.com/sendios/go-sdk/internal"
 )
 
-func TestEncrypt_Decrypt(t *testing.T) {
-	type fields struct {
-		block cipher.Block
+func TestMakeEncrypt(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{"aes_128", "444500b1bd43b59f", false},
+		{"aes_192", "444500b1bd43b59f444500b1", false},
+		{"aes_256", "444500b1bd43b59f444500b1bd43b59f", false},
+		{"invalid_length", "short", true},
 	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := internal.MakeEncrypt([]byte(tt.key))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MakeEncrypt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEncrypt_Decrypt(t *testing.T) {
 	type args struct {
 		encryptedData string
 	}
 	tests := []struct {
 		name    string
-		fields  fields
 		args    args
 		want    string
 		wantErr bool
 	}{
-		// TODO: Add test cases.
+		{"not_base64", args{"%%%"}, "", true},
+		{"too_short", args{base64.StdEncoding.EncodeToString([]byte("0123456789abcdef"))}, "", true},
+		{"not_block_multiple", args{base64.StdEncoding.EncodeToString(make([]byte, 40))}, "", true},
+		{"invalid_padding", args{base64.StdEncoding.EncodeToString(make([]byte, 32))}, "", true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			encrypt, err := internal.MakeEncrypt()
-			if (err != nil) != tt.wantErr {
-				t.Errorf("MakeEncrypt error = %v, wantErr %v", err, tt.wantErr)
-				return
+			encrypt, err := internal.MakeEncrypt([]byte("444500b1bd43b59f"))
+			if err != nil {
+				t.Fatalf("MakeEncrypt error = %v", err)
 			}
 
 			got, err := encrypt.Decrypt(tt.args.encryptedData)
@@ -43,37 +63,50 @@ func TestEncrypt_Decrypt(t *testing.T) {
 }
 
 func TestEncrypt_EncryptData(t *testing.T) {
-	type fields struct {
-		block cipher.Block
-	}
 	type args struct {
 		dataToEncrypt []byte
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    string
-		wantErr bool
+		name string
+		key  string
+		args args
 	}{
-		// TODO: Add test cases.
+		{"empty", "444500b1bd43b59f", args{[]byte{}}},
+		{"json", "444500b1bd43b59f", args{[]byte(`{"data":"test"}`)}},
+		{"exact_block", "444500b1bd43b59f", args{[]byte("0123456789abcdef")}},
+		{"trailing_spaces", "444500b1bd43b59f444500b1bd43b59f", args{[]byte("keep my spaces   ")}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			encrypt, err := internal.MakeEncrypt()
-			if (err != nil) != tt.wantErr {
-				t.Errorf("MakeEncrypt error = %v, wantErr %v", err, tt.wantErr)
-				return
+			encrypt, err := internal.MakeEncrypt([]byte(tt.key))
+			if err != nil {
+				t.Fatalf("MakeEncrypt error = %v", err)
 			}
 
 			got, err := encrypt.EncryptData(tt.args.dataToEncrypt)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("EncryptData() error = %v, wantErr %v", err, tt.wantErr)
-				return
+			if err != nil {
+				t.Fatalf("EncryptData() error = %v", err)
 			}
 
-			if got != tt.want {
-				t.Errorf("EncryptData() got = %v, want %v", got, tt.want)
+			again, err := encrypt.EncryptData(tt.args.dataToEncrypt)
+			if err != nil {
+				t.Fatalf("EncryptData() error = %v", err)
+			}
+			if got == again {
+				t.Errorf("EncryptData() produced the same ciphertext twice, IV is not random")
+			}
+
+			decrypted, err := encrypt.Decrypt(got)
+			if err != nil {
+				t.Fatalf("Decrypt() error = %v", err)
+			}
+			if decrypted != string(tt.args.dataToEncrypt) {
+				t.Errorf("Decrypt() got = %q, want %q", decrypted, tt.args.dataToEncrypt)
+			}
+
+			other, _ := internal.MakeEncrypt([]byte("fedcba9876543210"))
+			if decrypted, err := other.Decrypt(got); err == nil && decrypted == string(tt.args.dataToEncrypt) {
+				t.Errorf("Decrypt() with another key returned the plaintext")
 			}
 		})
 	}
@@ -1 +1,32 @@
 package tests
+
+import (
+	"os"
+	"testing"
+
+	"github.com/sendios/go-sdk/internal"
+)
+
+func TestLookupEnvVariable(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		set   bool
+		want  string
+	}{
+		{"from_process_environment", "0123456789abcdef", true, "0123456789abcdef"},
+		{"missing_without_env_file", "", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.set {
+				os.Setenv("SENDIOS_TEST_VARIABLE", tt.value)
+				defer os.Unsetenv("SENDIOS_TEST_VARIABLE")
+			}
+
+			if got := internal.LookupEnvVariable("SENDIOS_TEST_VARIABLE"); got != tt.want {
+				t.Errorf("LookupEnvVariable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sendios "github.com/sendios/go-sdk"
+)
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name           string
+		statusCode     int
+		body           string
+		wantMetaStatus string
+		wantMessage    string
+		wantCheck      func(err error) bool
+	}{
+		{"not_found", http.StatusNotFound,
+			`{"_meta":{"count":1,"status":"ERROR","time":4477},"data":{"error":"User not found"}}`,
+			"ERROR", "User not found", sendios.IsNotFound},
+		{"unauthorized", http.StatusUnauthorized,
+			`{"_meta":{"count":1,"status":"ERROR","time":4494},"data":{"error":"Forbidden"}}`,
+			"ERROR", "Forbidden", sendios.IsUnauthorized},
+		{"forbidden", http.StatusForbidden, `{"message":"Access denied"}`, "", "Access denied", sendios.IsForbidden},
+		{"rate_limited", http.StatusTooManyRequests, `{"_meta":{"status":"ERROR"},"data":[]}`, "ERROR", "", sendios.IsRateLimited},
+		{"html_error_page", http.StatusBadGateway, `<html><body>Bad gateway</body></html>`, "", "", sendios.IsServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer ts.Close()
+
+			sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", sendios.WithApiV1BaseUrl(ts.URL), sendios.WithRetryPolicy(sendios.RetryPolicy{MaxAttempts: 1}))
+
+			got, err := sdk.IsUnsubByEmailAndProjectIdCtx(context.Background(), "test@gmail.com", 2)
+			if got != nil {
+				t.Errorf("expected nil response, got %s", got)
+			}
+
+			var apiError *sendios.APIError
+			if !errors.As(err, &apiError) {
+				t.Fatalf("expected APIError, got %v", err)
+			}
+			if apiError.StatusCode != tt.statusCode {
+				t.Errorf("StatusCode = %v, want %v", apiError.StatusCode, tt.statusCode)
+			}
+			if apiError.MetaStatus != tt.wantMetaStatus {
+				t.Errorf("MetaStatus = %v, want %v", apiError.MetaStatus, tt.wantMetaStatus)
+			}
+			if apiError.Message != tt.wantMessage {
+				t.Errorf("Message = %v, want %v", apiError.Message, tt.wantMessage)
+			}
+			if apiError.Method != http.MethodGet || apiError.Route != "user/project/2/email/test@gmail.com" {
+				t.Errorf("unexpected request %s %s", apiError.Method, apiError.Route)
+			}
+			if string(apiError.Body) != tt.body {
+				t.Errorf("Body = %s, want %s", apiError.Body, tt.body)
+			}
+			if !tt.wantCheck(err) {
+				t.Errorf("status check failed for %v", err)
+			}
+			if sendios.IsNotFound(err) != (tt.statusCode == http.StatusNotFound) {
+				t.Errorf("IsNotFound() mismatch for %v", err)
+			}
+		})
+	}
+}
+
+func TestChainedLookups_NotFound(t *testing.T) {
+	tests := []struct {
+		name         string
+		lookupStatus int
+		lookupBody   string
+		call         func(sdk *sendios.SendiosSdk) error
+		want         error
+		wantAPIError bool
+	}{
+		{"unsub_status_404", http.StatusNotFound, `{"_meta":{"status":"ERROR"},"data":{"error":"Not found"}}`,
+			func(sdk *sendios.SendiosSdk) error {
+				_, err := sdk.IsUnsubByEmailAndProjectId("test@gmail.com", 2)
+				return err
+			}, sendios.ErrUserNotFound, true},
+		{"unsub_reason_error_meta", http.StatusOK, `{"_meta":{"status":"ERROR"},"data":{"error":"Not found"}}`,
+			func(sdk *sendios.SendiosSdk) error {
+				_, err := sdk.GetUnsubscribeReason("test@gmail.com", 2)
+				return err
+			}, sendios.ErrUserNotFound, false},
+		{"payment_without_user", http.StatusOK, `{"_meta":{"status":"SUCCESS"},"data":null}`,
+			func(sdk *sendios.SendiosSdk) error {
+				_, err := sdk.AddPaymentByEmailAndProjectId("test@gmail.com", 2, 1625479419, 1625479419, 1, 1, 100)
+				return err
+			}, sendios.ErrUserNotFound, false},
+		{"push_by_user_404", http.StatusNotFound, `{"_meta":{"status":"ERROR"},"data":{"error":"Not found"}}`,
+			func(sdk *sendios.SendiosSdk) error {
+				_, err := sdk.SendPushByEmailUserId(5005, "title", "text", "url", "icon", 1, nil, "")
+				return err
+			}, sendios.ErrPushUserNotFound, true},
+		{"push_by_hash_without_user", http.StatusOK, `{"_meta":{"status":"SUCCESS"},"data":{"result":null}}`,
+			func(sdk *sendios.SendiosSdk) error {
+				_, err := sdk.UnsubscribePushUserByProjectIdAndHash(2, "hash")
+				return err
+			}, sendios.ErrPushUserNotFound, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.URL.Path)
+				w.WriteHeader(tt.lookupStatus)
+				fmt.Fprint(w, tt.lookupBody)
+			}))
+			defer ts.Close()
+
+			sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", sendios.WithApiV1BaseUrl(ts.URL), sendios.WithRetryPolicy(sendios.RetryPolicy{MaxAttempts: 1}))
+
+			err := tt.call(sdk)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+			if sendios.IsNotFound(err) != tt.wantAPIError {
+				t.Errorf("IsNotFound(%v) = %v, want %v", err, sendios.IsNotFound(err), tt.wantAPIError)
+			}
+			if len(requests) != 1 {
+				t.Errorf("requests = %v, want only the lookup", requests)
+			}
+		})
+	}
+}
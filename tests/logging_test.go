@@ -0,0 +1,232 @@
+package tests
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	sendios "github.com/sendios/go-sdk"
+	"github.com/sendios/go-sdk/internal"
+	"github.com/sendios/go-sdk/sendiostest"
+)
+
+type logRecord struct {
+	level sendios.LogLevel
+	msg   string
+	attrs map[string]interface{}
+}
+
+type recordingLogger struct {
+	mu      sync.Mutex
+	records []logRecord
+}
+
+func (l *recordingLogger) Log(ctx context.Context, level sendios.LogLevel, msg string, args ...interface{}) {
+	attrs := map[string]interface{}{}
+	for i := 0; i+1 < len(args); i += 2 {
+		attrs[args[i].(string)] = args[i+1]
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, logRecord{level: level, msg: msg, attrs: attrs})
+}
+
+func (l *recordingLogger) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return fmt.Sprintf("%+v", l.records)
+}
+
+func TestWithLogger(t *testing.T) {
+	server := sendiostest.NewServer()
+	defer server.Close()
+	user := server.AddUser(sendiostest.User{Email: "secret.person@gmail.com", ProjectId: 2})
+	server.FailNext("GET user/id/{user}", 1, http.StatusServiceUnavailable)
+
+	logger := &recordingLogger{}
+	retry := sendios.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Multiplier: 1, RetryableStatuses: []int{http.StatusServiceUnavailable}}
+	options := append(server.Options(), sendios.WithLogger(logger), sendios.WithRetryPolicy(retry))
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", options...)
+
+	if _, err := sdk.GetEmailUserById(user.Id); err != nil {
+		t.Fatalf("GetEmailUserById() error = %v", err)
+	}
+
+	var outcomes []logRecord
+	for _, record := range logger.records {
+		if record.level != sendios.LogLevelDebug {
+			outcomes = append(outcomes, record)
+		}
+	}
+	if len(outcomes) != 2 {
+		t.Fatalf("records = %s", logger)
+	}
+
+	tests := []struct {
+		record      logRecord
+		wantLevel   sendios.LogLevel
+		wantStatus  int
+		wantAttempt int
+	}{
+		{outcomes[0], sendios.LogLevelWarn, http.StatusServiceUnavailable, 1},
+		{outcomes[1], sendios.LogLevelInfo, http.StatusOK, 2},
+	}
+	for _, tt := range tests {
+		attrs := tt.record.attrs
+		if tt.record.level != tt.wantLevel || attrs["status"] != tt.wantStatus || attrs["attempt"] != tt.wantAttempt {
+			t.Errorf("record = %+v, want level %v, status %v, attempt %v", tt.record, tt.wantLevel, tt.wantStatus, tt.wantAttempt)
+		}
+		if attrs["method"] != http.MethodGet || attrs["route"] != fmt.Sprintf("user/id/%d", user.Id) {
+			t.Errorf("record = %+v", tt.record)
+		}
+		if _, ok := attrs["latency"].(time.Duration); !ok {
+			t.Errorf("latency = %v", attrs["latency"])
+		}
+	}
+}
+
+func TestWithLogger_Redaction(t *testing.T) {
+	server := sendiostest.NewServer()
+	defer server.Close()
+	user := server.AddUser(sendiostest.User{Email: "secret.person@gmail.com", ProjectId: 2})
+
+	logger := &recordingLogger{}
+	options := append(server.Options(), sendios.WithLogger(logger))
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", options...)
+
+	if _, err := sdk.UnsubEmailUserByAdmin("secret.person@gmail.com", 2); err != nil {
+		t.Fatalf("UnsubEmailUserByAdmin() error = %v", err)
+	}
+	if _, err := sdk.GetEmailUserByEmailAndProjectId("unknown.person@gmail.com", 2); err == nil {
+		t.Fatal("GetEmailUserByEmailAndProjectId() for unknown email error = nil")
+	}
+	if _, err := sdk.CreatePushUser(user.Id, 2, "https://push.example.com/endpoint", "secret-public-key", "secret-auth-token"); err != nil {
+		t.Fatalf("CreatePushUser() error = %v", err)
+	}
+
+	logged := logger.String()
+	secrets := []string{
+		"secret.person@gmail.com",
+		"c2VjcmV0LnBlcnNvbkBnbWFpbC5jb20",
+		"unknown.person@gmail.com",
+		"secret-public-key",
+		"secret-auth-token",
+		internal.Sha1Encoder("VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"),
+	}
+	for _, secret := range secrets {
+		if strings.Contains(logged, secret) {
+			t.Errorf("log contains %q: %s", secret, logged)
+		}
+	}
+	if !strings.Contains(logged, internal.RedactEmail("unknown.person@gmail.com")) || !strings.Contains(logged, internal.RedactedValue) {
+		t.Errorf("log is missing redacted values: %s", logged)
+	}
+}
+
+func TestWithLogger_RedactsEscapedEncodedEmails(t *testing.T) {
+	server := sendiostest.NewServer()
+	defer server.Close()
+
+	logger := &recordingLogger{}
+	options := append(server.Options(), sendios.WithLogger(logger), sendios.WithRetryPolicy(sendios.RetryPolicy{MaxAttempts: 1}))
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", options...)
+
+	// the base64 of these emails holds + and /, which routes escape as %2B and %2F
+	for _, email := range []string{"zz>>@gmail.com", "user??@mail.org"} {
+		server.FailNext("POST unsub/admin/{project}/email/{email}", 1, http.StatusBadRequest)
+		if _, err := sdk.UnsubEmailUserByAdmin(email, 2); err == nil {
+			t.Fatalf("UnsubEmailUserByAdmin(%s) error = nil", email)
+		}
+	}
+
+	logged := logger.String()
+	for _, secret := range []string{"eno", "dXNlcj8", "zz>>", "user??"} {
+		if strings.Contains(logged, secret) {
+			t.Errorf("log contains %q: %s", secret, logged)
+		}
+	}
+}
+
+func TestRedactRoute(t *testing.T) {
+	tests := []struct {
+		name  string
+		route string
+		want  string
+	}{
+		{"plain", "user/project/2/email/test@gmail.com", "user/project/2/email/" + internal.RedactEmail("test@gmail.com")},
+		{"escaped_plus", "unsub/admin/2/email/eno%2BPkBnbWFpbC5jb20=", "unsub/admin/2/email/" + internal.EscapePathSegment(base64.StdEncoding.EncodeToString([]byte(internal.RedactEmail("zz>>@gmail.com"))))},
+		{"escaped_slash", "unsub/admin/2/email/dXNlcj8%2FQG1haWwub3Jn", "unsub/admin/2/email/" + internal.EscapePathSegment(base64.StdEncoding.EncodeToString([]byte(internal.RedactEmail("user??@mail.org"))))},
+		{"no_email", "unsub/unsubreason/42", "unsub/unsubreason/42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := internal.RedactRoute(tt.route); got != tt.want {
+				t.Errorf("RedactRoute() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{"empty", "", ""},
+		{"not_json", "to test@gmail.com", "to " + internal.RedactEmail("test@gmail.com")},
+		{"nested_email", `{"user":{"email":"Test@gmail.com"},"ids":[1,2]}`, `{"ids":[1,2],"user":{"email":"` + internal.RedactEmail("test@gmail.com") + `"}}`},
+		{"encoded_email", `{"encoded_email":"dXNlcj8/QG1haWwub3Jn"}`, `{"encoded_email":"` + base64.StdEncoding.EncodeToString([]byte(internal.RedactEmail("user??@mail.org"))) + `"}`},
+		{"push_keys", `{"meta":{"auth_token":"a","public_key":"b","url":"u"}}`, `{"meta":{"auth_token":"REDACTED","public_key":"REDACTED","url":"u"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := internal.RedactJSON([]byte(tt.payload)); got != tt.want {
+				t.Errorf("RedactJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithLogger_WarnsOnceAboutLegacyEncryptionKey(t *testing.T) {
+	server := sendiostest.NewServer()
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		opts      []sendios.Option
+		wantWarns int
+	}{
+		{"legacy_key", []sendios.Option{sendios.WithEncryptionKey(""), sendios.WithLegacyEncryptionKey()}, 1},
+		{"configured_key", []sendios.Option{sendios.WithEncryptionKey("0123456789abcdef")}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &recordingLogger{}
+			sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", append(append(server.Options(), sendios.WithLogger(logger)), tt.opts...)...)
+
+			for i := 0; i < 2; i++ {
+				if _, err := sdk.SendEmail(3, 1, sendios.Trigger, 2, "test@gmail.com", nil, map[string]string{"data": "test"}, nil); err != nil {
+					t.Fatalf("SendEmail() error = %v", err)
+				}
+			}
+
+			warns := 0
+			for _, record := range logger.records {
+				if record.level == sendios.LogLevelWarn && strings.Contains(record.msg, "legacy key") {
+					warns++
+				}
+			}
+			if warns != tt.wantWarns {
+				t.Errorf("legacy key warnings = %d, want %d: %s", warns, tt.wantWarns, logger)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sendios "github.com/sendios/go-sdk"
+	"github.com/sendios/go-sdk/sendiostest"
+)
+
+func TestMetrics(t *testing.T) {
+	server := sendiostest.NewServer()
+	defer server.Close()
+	user := server.AddUser(sendiostest.User{Email: "test@gmail.com", ProjectId: 2})
+	server.FailNext("GET user/id/{user}", 1, http.StatusServiceUnavailable)
+
+	metrics := sendios.NewMetrics()
+	retry := sendios.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Multiplier: 1, RetryableStatuses: []int{http.StatusServiceUnavailable}}
+	options := append(server.Options(), sendios.WithMetrics(metrics), sendios.WithRetryPolicy(retry))
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", options...)
+
+	if sdk.Metrics() != metrics {
+		t.Fatal("Metrics() did not return the collector passed to WithMetrics")
+	}
+	if _, err := sdk.GetEmailUserById(user.Id); err != nil {
+		t.Fatalf("GetEmailUserById() error = %v", err)
+	}
+	if _, err := sdk.GetEmailUserById(999); err == nil {
+		t.Fatal("GetEmailUserById() for unknown user error = nil")
+	}
+	if _, err := sdk.SetOnlineByUser(user.Id); err != nil {
+		t.Fatalf("SetOnlineByUser() error = %v", err)
+	}
+
+	ts := httptest.NewServer(sdk.Metrics())
+	defer ts.Close()
+
+	response, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	body, _ := ioutil.ReadAll(response.Body)
+	exposition := string(body)
+
+	if contentType := response.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %v", contentType)
+	}
+
+	tests := []string{
+		"# TYPE sendios_requests_total counter",
+		`sendios_requests_total{operation="GetEmailUserById"} 2`,
+		`sendios_requests_total{operation="SetOnlineByUser"} 1`,
+		`sendios_request_errors_total{operation="GetEmailUserById",class="4xx"} 1`,
+		`sendios_request_retries_total{operation="GetEmailUserById"} 1`,
+		`sendios_request_retries_total{operation="SetOnlineByUser"} 0`,
+		"# TYPE sendios_request_duration_seconds histogram",
+		`sendios_request_duration_seconds_bucket{operation="SetOnlineByUser",le="+Inf"} 1`,
+		`sendios_request_duration_seconds_count{operation="GetEmailUserById"} 2`,
+	}
+	for _, want := range tests {
+		if !strings.Contains(exposition, want+"\n") {
+			t.Errorf("exposition is missing %q:\n%s", want, exposition)
+		}
+	}
+	if strings.Contains(exposition, "user/id/") {
+		t.Errorf("exposition contains raw routes:\n%s", exposition)
+	}
+}
+
+func TestMetrics_Nil(t *testing.T) {
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", sendios.WithMetrics(nil))
+
+	var b strings.Builder
+	if err := sdk.Metrics().WritePrometheus(&b); err != nil || b.Len() != 0 {
+		t.Errorf("WritePrometheus() = %q, %v", b.String(), err)
+	}
+
+	recorder := httptest.NewRecorder()
+	sdk.Metrics().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK || recorder.Body.Len() != 0 {
+		t.Errorf("ServeHTTP() = %d %q", recorder.Code, recorder.Body.String())
+	}
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	sendios "github.com/sendios/go-sdk"
+	"github.com/sendios/go-sdk/sendiostest"
+)
+
+func TestWithMiddleware(t *testing.T) {
+	server := sendiostest.NewServer()
+	defer server.Close()
+	user := server.AddUser(sendiostest.User{Email: "test@gmail.com", ProjectId: 2})
+
+	var order []string
+	var calls []sendios.Call
+	var responses []*sendios.CallResponse
+	recording := func(name string) sendios.Middleware {
+		return func(next sendios.Handler) sendios.Handler {
+			return func(ctx context.Context, call *sendios.Call) (*sendios.CallResponse, error) {
+				order = append(order, name)
+				call.Header.Add("X-Chain", name)
+				response, err := next(ctx, call)
+				if name == "outer" {
+					calls = append(calls, *call)
+					responses = append(responses, response)
+				}
+
+				return response, err
+			}
+		}
+	}
+
+	options := append(server.Options(), sendios.WithMiddleware(recording("outer"), recording("inner")))
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", options...)
+
+	got, err := sdk.SubscribeEmailUser(user.Id)
+	if err != nil {
+		t.Fatalf("SubscribeEmailUser() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(order, []string{"outer", "inner"}) {
+		t.Errorf("order = %v", order)
+	}
+	if len(calls) != 1 || calls[0].Method != "DELETE" || calls[0].BaseUrl != server.URL+"/v1/" || calls[0].Route != "unsub/1" {
+		t.Fatalf("calls = %+v", calls)
+	}
+	if responses[0] == nil || responses[0].StatusCode != http.StatusOK {
+		t.Errorf("middleware response = %+v", responses[0])
+	}
+	if out, ok := calls[0].Out.(*[]byte); !ok || !reflect.DeepEqual(*out, got) {
+		t.Errorf("middleware out = %v, want %s", calls[0].Out, got)
+	}
+
+	requests := server.Requests()
+	if header := requests[len(requests)-1].Header["X-Chain"]; !reflect.DeepEqual(header, []string{"outer", "inner"}) {
+		t.Errorf("X-Chain header = %v", header)
+	}
+}
+
+func TestWithMiddleware_ShortCircuit(t *testing.T) {
+	server := sendiostest.NewServer()
+	defer server.Close()
+
+	blocked := errors.New("blocked")
+	options := append(server.Options(), sendios.WithMiddleware(func(next sendios.Handler) sendios.Handler {
+		return func(ctx context.Context, call *sendios.Call) (*sendios.CallResponse, error) {
+			if call.Route == "webpush/send" {
+				return nil, blocked
+			}
+
+			return next(ctx, call)
+		}
+	}))
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", options...)
+
+	if _, err := sdk.SendPushByProject(1, "title", "text", "url", "icon", 1, nil, ""); !errors.Is(err, blocked) {
+		t.Errorf("SendPushByProject() error = %v, want %v", err, blocked)
+	}
+	if requests := server.Requests(); len(requests) != 0 {
+		t.Errorf("requests = %+v, want none", requests)
+	}
+}
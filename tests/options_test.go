@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	sendios "github.com/sendios/go-sdk"
+	"github.com/sendios/go-sdk/internal"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestNewSendiosSdk_BaseUrls(t *testing.T) {
+	tests := []struct {
+		name     string
+		call     func(sdk *sendios.SendiosSdk) ([]byte, error)
+		wantPath string
+	}{
+		{"v1_route", func(sdk *sendios.SendiosSdk) ([]byte, error) { return sdk.GetEmailUserById(5005) }, "/v1/user/id/5005"},
+		{"v3_route", func(sdk *sendios.SendiosSdk) ([]byte, error) { return sdk.SetOnlineByUser(5005) }, "/v3/users/5005/online"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath, gotUserAgent string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				gotUserAgent = r.UserAgent()
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				fmt.Fprintln(w, `{"status":"Accepted"}`)
+			}))
+			defer ts.Close()
+
+			sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6",
+				sendios.WithApiV1BaseUrl(ts.URL+"/v1"),
+				sendios.WithApiV3BaseUrl(ts.URL+"/v3/"),
+				sendios.WithUserAgent("my-service/1.0"),
+			)
+
+			got, err := tt.call(sdk)
+			if err != nil {
+				t.Fatalf("expected error to be nil got %v", err)
+			}
+			if !reflect.DeepEqual(got, []byte(`{"status":"Accepted"}`)) {
+				t.Errorf("got = %s", got)
+			}
+			if gotPath != tt.wantPath {
+				t.Errorf("path = %v, want %v", gotPath, tt.wantPath)
+			}
+			if gotUserAgent != "my-service/1.0" {
+				t.Errorf("user agent = %v, want %v", gotUserAgent, "my-service/1.0")
+			}
+		})
+	}
+}
+
+func TestNewSendiosSdk_HttpClientOptions(t *testing.T) {
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, fmt.Errorf("not used")
+	})
+	callerClient := &http.Client{Timeout: time.Minute}
+
+	tests := []struct {
+		name          string
+		opts          []sendios.Option
+		wantTimeout   time.Duration
+		wantTransport bool
+		wantSame      bool
+	}{
+		{"default", nil, sendios.DefaultTimeout, false, false},
+		{"timeout", []sendios.Option{sendios.WithTimeout(time.Second)}, time.Second, false, false},
+		{"transport", []sendios.Option{sendios.WithTransport(transport)}, sendios.DefaultTimeout, true, false},
+		{"caller_client", []sendios.Option{sendios.WithHttpClient(callerClient)}, time.Minute, false, true},
+		{"caller_client_with_timeout", []sendios.Option{sendios.WithHttpClient(callerClient), sendios.WithTimeout(time.Second)}, time.Second, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", tt.opts...).Request.Client
+
+			if client.Timeout != tt.wantTimeout {
+				t.Errorf("timeout = %v, want %v", client.Timeout, tt.wantTimeout)
+			}
+			if (client.Transport != nil) != tt.wantTransport {
+				t.Errorf("transport = %v, want set %v", client.Transport, tt.wantTransport)
+			}
+			if (client == callerClient) != tt.wantSame {
+				t.Errorf("client reused = %v, want %v", client == callerClient, tt.wantSame)
+			}
+			if callerClient.Timeout != time.Minute {
+				t.Errorf("caller client was modified")
+			}
+		})
+	}
+}
+
+func TestNewSendiosSdk_EncryptionKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		envKey  string
+		opts    []sendios.Option
+		wantKey string
+	}{
+		{"legacy_opt_in", "", []sendios.Option{sendios.WithLegacyEncryptionKey()}, "444500b1bd43b59f"},
+		{"env_over_legacy", "0123456789abcdef", []sendios.Option{sendios.WithLegacyEncryptionKey()}, "0123456789abcdef"},
+		{"env", "0123456789abcdef", nil, "0123456789abcdef"},
+		{"option_overrides_env", "0123456789abcdef", []sendios.Option{sendios.WithEncryptionKey("fedcba9876543210fedcba9876543210")}, "fedcba9876543210fedcba9876543210"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.envKey != "" {
+				os.Setenv(sendios.EncryptionKeyEnv, tt.envKey)
+				defer os.Unsetenv(sendios.EncryptionKeyEnv)
+			}
+
+			var sent struct {
+				ValueEncrypt struct {
+					TemplateData string `json:"template_data"`
+				} `json:"value_encrypt"`
+			}
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewDecoder(r.Body).Decode(&sent)
+				fmt.Fprint(w, `{"_meta":{"status":"SUCCESS"},"data":null}`)
+			}))
+			defer ts.Close()
+
+			sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", append(tt.opts, sendios.WithApiV1BaseUrl(ts.URL))...)
+			if _, err := sdk.SendEmail(3, 1, sendios.Trigger, 2, "test@gmail.com", map[string]string{}, map[string]string{"data": "test"}, nil); err != nil {
+				t.Fatalf("expected error to be nil got %v", err)
+			}
+
+			encrypt, err := internal.MakeEncrypt([]byte(tt.wantKey))
+			if err != nil {
+				t.Fatalf("MakeEncrypt error = %v", err)
+			}
+
+			got, err := encrypt.Decrypt(sent.ValueEncrypt.TemplateData)
+			if err != nil {
+				t.Fatalf("Decrypt() error = %v", err)
+			}
+			if got != `{"data":"test"}` {
+				t.Errorf("template data = %v, want %v", got, `{"data":"test"}`)
+			}
+		})
+	}
+}
+
+func TestNewSendiosSdk_WithoutEncryptionKey(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `{"_meta":{"status":"SUCCESS"},"data":null}`)
+	}))
+	defer ts.Close()
+
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", sendios.WithApiV1BaseUrl(ts.URL))
+	_, err := sdk.SendEmail(3, 1, sendios.Trigger, 2, "test@gmail.com", map[string]string{}, map[string]string{"data": "test"}, nil)
+	if !errors.Is(err, sendios.ErrNoEncryptionKey) {
+		t.Errorf("SendEmail() error = %v, want %v", err, sendios.ErrNoEncryptionKey)
+	}
+	if requests != 0 {
+		t.Errorf("requests = %d, want 0", requests)
+	}
+}
@@ -0,0 +1,414 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	sendios "github.com/sendios/go-sdk"
+	"github.com/sendios/go-sdk/internal"
+	"github.com/sendios/go-sdk/sendiostest"
+)
+
+type outboxTestServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	keys     []string
+	paths    []string
+	failWith int
+}
+
+func newOutboxTestServer() *outboxTestServer {
+	s := &outboxTestServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.keys = append(s.keys, r.Header.Get("Idempotency-Key"))
+		s.paths = append(s.paths, r.URL.Path)
+		failWith := s.failWith
+		s.mu.Unlock()
+
+		if failWith != 0 {
+			w.WriteHeader(failWith)
+			fmt.Fprint(w, `{"_meta":{"status":"ERROR"},"data":{"error":"rejected"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"_meta":{"status":"SUCCESS"},"data":null}`)
+	}))
+
+	return s
+}
+
+func newOutboxTestSdk(url string) *sendios.SendiosSdk {
+	return sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6",
+		sendios.WithApiV1BaseUrl(url),
+		sendios.WithEncryptionKey("0123456789abcdef"),
+		sendios.WithRetryPolicy(sendios.RetryPolicy{MaxAttempts: 1}),
+	)
+}
+
+func TestOutbox_DeliversAndReplays(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ts := newOutboxTestServer()
+	defer ts.Close()
+	sdk := newOutboxTestSdk(ts.URL)
+
+	outbox, err := sdk.NewOutbox(sendios.OutboxOptions{Dir: dir})
+	if err != nil {
+		t.Fatalf("NewOutbox() error = %v", err)
+	}
+
+	msg := sendios.NewEmailMessage("test@gmail.com").WithProject(2).WithType(sendios.Trigger, 1).WithData(map[string]string{"data": "test"})
+	if err := outbox.EnqueueEmail("email-1", msg); err != nil {
+		t.Fatalf("EnqueueEmail() error = %v", err)
+	}
+	if err := outbox.EnqueuePush("push-1", sendios.PushMessage{ProjectId: 2, Title: "title", Text: "text"}); err != nil {
+		t.Fatalf("EnqueuePush() error = %v", err)
+	}
+	if err := outbox.EnqueueEmail("email-1", msg); err != nil {
+		t.Fatalf("EnqueueEmail() duplicate error = %v", err)
+	}
+	if err := outbox.EnqueueEmail("", msg); err != sendios.ErrEmptyIdempotencyKey {
+		t.Errorf("EnqueueEmail() with empty key error = %v", err)
+	}
+
+	// Simulate a crash: the process goes away before flushing.
+	if err := outbox.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	outbox, err = sdk.NewOutbox(sendios.OutboxOptions{Dir: dir})
+	if err != nil {
+		t.Fatalf("NewOutbox() error = %v", err)
+	}
+
+	pending := outbox.Pending()
+	if len(pending) != 2 || pending[0].Key != "email-1" || pending[1].Key != "push-1" {
+		t.Fatalf("pending after restart = %+v", pending)
+	}
+
+	if err := outbox.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(outbox.Pending()) != 0 {
+		t.Errorf("pending after flush = %+v", outbox.Pending())
+	}
+	if fmt.Sprint(ts.paths) != "[/push/trigger /webpush/send]" || fmt.Sprint(ts.keys) != "[email-1 push-1]" {
+		t.Errorf("sent paths = %v, keys = %v", ts.paths, ts.keys)
+	}
+
+	if err := outbox.EnqueueEmail("email-1", msg); err != nil {
+		t.Fatalf("EnqueueEmail() delivered duplicate error = %v", err)
+	}
+	outbox.Close()
+
+	outbox, err = sdk.NewOutbox(sendios.OutboxOptions{Dir: dir})
+	if err != nil {
+		t.Fatalf("NewOutbox() error = %v", err)
+	}
+	defer outbox.Close()
+
+	if err := outbox.EnqueueEmail("email-1", msg); err != nil {
+		t.Fatalf("EnqueueEmail() error = %v", err)
+	}
+	if len(outbox.Pending()) != 0 {
+		t.Errorf("delivered key was enqueued again after restart")
+	}
+}
+
+func TestOutbox_DeadLetters(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ts := newOutboxTestServer()
+	ts.failWith = http.StatusBadRequest
+	defer ts.Close()
+	sdk := newOutboxTestSdk(ts.URL)
+
+	outbox, err := sdk.NewOutbox(sendios.OutboxOptions{Dir: dir, MaxAttempts: 3})
+	if err != nil {
+		t.Fatalf("NewOutbox() error = %v", err)
+	}
+
+	if err := outbox.EnqueuePush("push-1", sendios.PushMessage{PushUserId: 717067, Title: "title"}); err != nil {
+		t.Fatalf("EnqueuePush() error = %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := outbox.Flush(context.Background()); err != nil {
+			t.Fatalf("Flush() error = %v", err)
+		}
+	}
+	outbox.Close()
+
+	outbox, err = sdk.NewOutbox(sendios.OutboxOptions{Dir: dir, MaxAttempts: 3})
+	if err != nil {
+		t.Fatalf("NewOutbox() error = %v", err)
+	}
+	defer outbox.Close()
+
+	pending := outbox.Pending()
+	if len(pending) != 1 || pending[0].Attempts != 2 {
+		t.Fatalf("pending after restart = %+v", pending)
+	}
+
+	if err := outbox.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(outbox.Pending()) != 0 {
+		t.Errorf("poison message is still pending")
+	}
+
+	file, err := os.Open(filepath.Join(dir, "dead_letters.journal"))
+	if err != nil {
+		t.Fatalf("dead letter file error = %v", err)
+	}
+	defer file.Close()
+
+	var lines []sendios.OutboxEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry sendios.OutboxEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid dead letter %s", scanner.Bytes())
+		}
+		lines = append(lines, entry)
+	}
+
+	if len(lines) != 1 || lines[0].Key != "push-1" || lines[0].Attempts != 3 || lines[0].LastError == "" {
+		t.Errorf("dead letters = %+v", lines)
+	}
+
+	var payload internal.WebpushSend
+	if err := json.Unmarshal(lines[0].Payload, &payload); err != nil || payload.PushUserId != 717067 {
+		t.Errorf("dead letter payload = %s", lines[0].Payload)
+	}
+}
+
+func TestOutbox_ForgetsKeysAfterRetention(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ts := newOutboxTestServer()
+	defer ts.Close()
+	sdk := newOutboxTestSdk(ts.URL)
+
+	outbox, err := sdk.NewOutbox(sendios.OutboxOptions{Dir: dir})
+	if err != nil {
+		t.Fatalf("NewOutbox() error = %v", err)
+	}
+	msg := sendios.PushMessage{ProjectId: 2, Title: "title"}
+	if err := outbox.EnqueuePush("push-1", msg); err != nil {
+		t.Fatalf("EnqueuePush() error = %v", err)
+	}
+	if err := outbox.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	outbox.Close()
+
+	tests := []struct {
+		name      string
+		retention time.Duration
+		pending   int
+	}{
+		{"within retention", time.Hour, 0},
+		{"after retention", time.Nanosecond, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outbox, err := sdk.NewOutbox(sendios.OutboxOptions{Dir: dir, Retention: tt.retention})
+			if err != nil {
+				t.Fatalf("NewOutbox() error = %v", err)
+			}
+			defer outbox.Close()
+
+			if err := outbox.EnqueuePush("push-1", msg); err != nil {
+				t.Fatalf("EnqueuePush() error = %v", err)
+			}
+			if got := len(outbox.Pending()); got != tt.pending {
+				t.Errorf("pending = %d, want %d", got, tt.pending)
+			}
+		})
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "outbox.journal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 1 {
+		t.Errorf("journal has %d records after compaction, want the pending one:\n%s", lines, data)
+	}
+}
+
+func TestOutbox_RejectsInvalidPush(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	outbox, err := newOutboxTestSdk("http://127.0.0.1:0").NewOutbox(sendios.OutboxOptions{Dir: dir})
+	if err != nil {
+		t.Fatalf("NewOutbox() error = %v", err)
+	}
+	defer outbox.Close()
+
+	tests := []struct {
+		name string
+		msg  sendios.PushMessage
+	}{
+		{"no recipient", sendios.PushMessage{Title: "title"}},
+		{"no title", sendios.PushMessage{PushUserId: 717067}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := outbox.EnqueuePush(tt.name, tt.msg); !errors.Is(err, sendios.ErrInvalidPushMessage) {
+				t.Errorf("EnqueuePush() error = %v", err)
+			}
+		})
+	}
+	if len(outbox.Pending()) != 0 {
+		t.Errorf("invalid messages were journaled: %+v", outbox.Pending())
+	}
+}
+
+func TestOutbox_SkipsSuppressedEmails(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	server := sendiostest.NewServer()
+	defer server.Close()
+
+	list, err := sendios.NewSuppressionList("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", append(server.Options(),
+		sendios.WithRetryPolicy(sendios.RetryPolicy{MaxAttempts: 1}),
+		sendios.WithSuppressionList(list),
+		sendios.WithSuppressionCheck(),
+	)...)
+
+	outbox, err := sdk.NewOutbox(sendios.OutboxOptions{Dir: dir})
+	if err != nil {
+		t.Fatalf("NewOutbox() error = %v", err)
+	}
+	defer outbox.Close()
+
+	msg := sendios.NewEmailMessage("test@gmail.com").WithProject(2).WithType(sendios.Trigger, 1)
+	if err := outbox.EnqueueEmail("email-1", msg); err != nil {
+		t.Fatalf("EnqueueEmail() error = %v", err)
+	}
+
+	if err := list.Add(sendios.Suppression{ProjectId: 2, Email: "test@gmail.com", All: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.EnqueueEmail("email-2", msg); !errors.Is(err, sendios.ErrSuppressed) {
+		t.Errorf("EnqueueEmail() error = %v, want ErrSuppressed", err)
+	}
+
+	if err := outbox.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(server.SentEmails()) != 0 {
+		t.Errorf("suppressed email was sent: %+v", server.SentEmails())
+	}
+	if len(outbox.Pending()) != 0 {
+		t.Errorf("suppressed email is still pending: %+v", outbox.Pending())
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "dead_letters.journal"))
+	if err != nil || !strings.Contains(string(data), "email-1") {
+		t.Errorf("dead letters = %s, error = %v", data, err)
+	}
+}
+
+func TestJournal_SkipsTornRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.journal")
+	if err := ioutil.WriteFile(path, []byte("{\"op\":\"done\",\"key\":\"a\"}\n{\"op\":\"enq"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	journal, records, err := internal.OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal() error = %v", err)
+	}
+	if len(records) != 1 || string(records[0]) != `{"op":"done","key":"a"}` {
+		t.Errorf("records = %s", records)
+	}
+
+	if err := journal.Append(map[string]string{"op": "done", "key": "b"}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	journal.Close()
+
+	journal, records, err = internal.OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal() error = %v", err)
+	}
+	defer journal.Close()
+
+	if len(records) != 2 || string(records[1]) != `{"key":"b","op":"done"}` {
+		t.Errorf("records after append = %s", records)
+	}
+}
+
+func TestJournal_AppendsAfterFailedRewrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.journal")
+	journal, _, err := internal.OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal() error = %v", err)
+	}
+	defer journal.Close()
+
+	// a non-empty directory in place of the journal makes the rename fail
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(path, "blocked"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := journal.Rewrite([]interface{}{map[string]string{"op": "done", "key": "a"}}); err == nil {
+		t.Fatal("Rewrite() error = nil, want an error")
+	}
+	if err := journal.Append(map[string]string{"op": "done", "key": "b"}); err != nil {
+		t.Errorf("Append() after failed Rewrite error = %v", err)
+	}
+}
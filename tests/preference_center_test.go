@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	sendios "github.com/sendios/go-sdk"
+	"github.com/sendios/go-sdk/sendiostest"
+)
+
+func newPreferenceCenterTest(t *testing.T, tmpl *template.Template) (*sendiostest.Server, *sendios.PreferenceCenter, *httptest.Server, *http.Client) {
+	server := sendiostest.NewServer()
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", append(server.Options(), sendios.WithRetryPolicy(sendios.RetryPolicy{MaxAttempts: 1}))...)
+
+	center, err := sdk.NewPreferenceCenter(sendios.PreferenceCenterOptions{
+		Secret:   []byte("secret"),
+		Types:    []sendios.PreferenceType{{TypeId: 1, Name: "News"}, {TypeId: 2, Name: "Offers"}},
+		Template: tmpl,
+	})
+	if err != nil {
+		t.Fatalf("NewPreferenceCenter() error = %v", err)
+	}
+
+	ts := httptest.NewServer(center)
+	client := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	return server, center, ts, client
+}
+
+func readPage(t *testing.T, client *http.Client, target string) (int, string) {
+	response, err := client.Get(target)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	defer response.Body.Close()
+
+	body, _ := ioutil.ReadAll(response.Body)
+
+	return response.StatusCode, string(body)
+}
+
+func TestPreferenceCenter_Tokens(t *testing.T) {
+	server, center, ts, client := newPreferenceCenterTest(t, nil)
+	defer server.Close()
+	defer ts.Close()
+
+	user := server.AddUser(sendiostest.User{Email: "test@gmail.com", ProjectId: 2})
+	valid := center.Token(user.Id, time.Now().Add(time.Hour))
+
+	other, err := (&sendios.SendiosSdk{}).NewPreferenceCenter(sendios.PreferenceCenterOptions{Secret: []byte("other")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{"valid", valid, http.StatusOK},
+		{"missing", "", http.StatusForbidden},
+		{"other secret", other.Token(user.Id, time.Now().Add(time.Hour)), http.StatusForbidden},
+		{"tampered user", strings.Replace(valid, "1.", "2.", 1), http.StatusForbidden},
+		{"expired", center.Token(user.Id, time.Now().Add(-time.Minute)), http.StatusForbidden},
+		{"unknown user", center.Token(42, time.Now().Add(time.Hour)), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, body := readPage(t, client, ts.URL+"?token="+url.QueryEscape(tt.token)); status != tt.status {
+				t.Errorf("status = %d, want %d: %s", status, tt.status, body)
+			}
+		})
+	}
+
+	if _, err := center.ParseToken(center.Token(user.Id, time.Now().Add(-time.Minute))); err != sendios.ErrExpiredPreferenceToken {
+		t.Errorf("ParseToken() error = %v", err)
+	}
+	if _, err := (&sendios.SendiosSdk{}).NewPreferenceCenter(sendios.PreferenceCenterOptions{}); err != sendios.ErrNoPreferenceCenterSecret {
+		t.Errorf("NewPreferenceCenter() without secret error = %v", err)
+	}
+
+	request, _ := http.NewRequest(http.MethodPut, ts.URL+"?token="+url.QueryEscape(valid), nil)
+	response, err := client.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("PUT status = %d", response.StatusCode)
+	}
+}
+
+func TestPreferenceCenter_RendersAndSaves(t *testing.T) {
+	tmpl := template.Must(template.New("custom").Parse(
+		`{{range .Types}}{{.Name}}={{.Subscribed}};{{end}}all={{.UnsubscribedFromAll}};saved={{.Saved}}`))
+	server, center, ts, client := newPreferenceCenterTest(t, tmpl)
+	defer server.Close()
+	defer ts.Close()
+
+	user := server.AddUser(sendiostest.User{Email: "test@gmail.com", ProjectId: 2, UnsubscribedTypes: []int{1, 7}})
+	token := center.Token(user.Id, time.Now().Add(time.Hour))
+
+	status, body := readPage(t, client, ts.URL+"/preferences?token="+url.QueryEscape(token))
+	if status != http.StatusOK || body != "News=false;Offers=true;all=false;saved=false" {
+		t.Fatalf("page = %d %q", status, body)
+	}
+
+	post := func(form url.Values) {
+		form.Set("token", token)
+		response, err := client.PostForm(ts.URL+"/preferences", form)
+		if err != nil {
+			t.Fatalf("POST error = %v", err)
+		}
+		response.Body.Close()
+
+		want := "?" + url.Values{"saved": {"1"}, "token": {token}}.Encode()
+		if response.StatusCode != http.StatusSeeOther || response.Header.Get("Location") != want {
+			t.Fatalf("POST = %d, location %q", response.StatusCode, response.Header.Get("Location"))
+		}
+	}
+
+	// subscribes to 1 and unsubscribes from 2, type 7 is not managed by the center
+	post(url.Values{"type": {"1", "9"}})
+	if stored, _ := server.User(user.Id); !reflect.DeepEqual(stored.UnsubscribedTypes, []int{2, 7}) || stored.Unsubscribed {
+		t.Errorf("stored user = %+v", stored)
+	}
+	if _, body := readPage(t, client, ts.URL+"/preferences?saved=1&token="+url.QueryEscape(token)); body != "News=true;Offers=false;all=false;saved=true" {
+		t.Errorf("page after save = %q", body)
+	}
+
+	post(url.Values{"type": {"1"}, "unsubscribe_all": {"1"}})
+	if !server.IsUnsubscribed(user.Id) {
+		t.Errorf("user was not unsubscribed from all")
+	}
+
+	// a form which did not show the user unsubscribed from all resubscribes nobody
+	post(url.Values{"type": {"1"}})
+	if !server.IsUnsubscribed(user.Id) {
+		t.Errorf("user was resubscribed by a form without the shown state")
+	}
+
+	post(url.Values{"type": {"1", "2"}, "unsubscribed_all": {"1"}})
+	if stored, _ := server.User(user.Id); !reflect.DeepEqual(stored.UnsubscribedTypes, []int{7}) || stored.Unsubscribed {
+		t.Errorf("stored user after resubscribing = %+v", stored)
+	}
+}
+
+func TestPreferenceCenter_KeepsAdminUnsubscribe(t *testing.T) {
+	tmpl := template.Must(template.New("custom").Parse(`all={{.UnsubscribedFromAll}};resubscribe={{.CanResubscribe}}`))
+	server, center, ts, client := newPreferenceCenterTest(t, tmpl)
+	defer server.Close()
+	defer ts.Close()
+
+	user := server.AddUser(sendiostest.User{Email: "test@gmail.com", ProjectId: 2})
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", server.Options()...)
+	if _, err := sdk.UnsubEmailUserByAdmin("test@gmail.com", 2); err != nil {
+		t.Fatalf("UnsubEmailUserByAdmin() error = %v", err)
+	}
+	token := center.Token(user.Id, time.Now().Add(time.Hour))
+
+	if _, body := readPage(t, client, ts.URL+"?token="+url.QueryEscape(token)); body != "all=true;resubscribe=false" {
+		t.Errorf("page = %q", body)
+	}
+
+	response, err := client.PostForm(ts.URL, url.Values{"token": {token}, "type": {"1", "2"}, "unsubscribed_all": {"1"}})
+	if err != nil {
+		t.Fatalf("POST error = %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusSeeOther {
+		t.Errorf("POST status = %d", response.StatusCode)
+	}
+	if !server.IsUnsubscribed(user.Id) {
+		t.Errorf("admin unsubscribe was undone by the preference center")
+	}
+}
+
+func TestPreferenceCenter_DefaultTemplate(t *testing.T) {
+	server, center, ts, client := newPreferenceCenterTest(t, nil)
+	defer server.Close()
+	defer ts.Close()
+
+	user := server.AddUser(sendiostest.User{Email: "test@gmail.com", ProjectId: 2})
+	token := center.Token(user.Id, time.Now().Add(time.Hour))
+
+	_, body := readPage(t, client, ts.URL+"?token="+url.QueryEscape(token))
+	if strings.Contains(body, `name="unsubscribed_all"`) {
+		t.Errorf("page of a subscribed user holds the unsubscribed state: %s", body)
+	}
+
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", server.Options()...)
+	if _, err := sdk.UnsubEmailUserBySettings(user.Id); err != nil {
+		t.Fatalf("UnsubEmailUserBySettings() error = %v", err)
+	}
+	_, body = readPage(t, client, ts.URL+"?token="+url.QueryEscape(token))
+	if !strings.Contains(body, `name="unsubscribed_all" value="1"`) || strings.Contains(body, "disabled") {
+		t.Errorf("page of a user unsubscribed by settings = %s", body)
+	}
+}
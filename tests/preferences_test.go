@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	sendios "github.com/sendios/go-sdk"
+	"github.com/sendios/go-sdk/sendiostest"
+)
+
+func TestUpdatePreferences(t *testing.T) {
+	tests := []struct {
+		name         string
+		unsubscribed []int
+		subscribe    []int
+		unsubscribe  []int
+		wantChange   sendios.PreferenceChange
+		wantRequest  string
+		wantTypes    []int
+	}{
+		{"no change", []int{1, 2}, []int{3}, []int{1}, sendios.PreferenceChange{}, "", []int{1, 2}},
+		{"only unsubscribe", []int{1}, nil, []int{1, 4, 3, 4}, sendios.PreferenceChange{Unsubscribe: []int{3, 4}}, "POST unsubtypes/nodiff/1", []int{1, 3, 4}},
+		{"only subscribe", []int{1, 2, 3}, []int{2, 5}, nil, sendios.PreferenceChange{Subscribe: []int{2}}, "DELETE unsubtypes/nodiff/1", []int{1, 3}},
+		{"subscribe to all", []int{1, 2}, []int{1, 2}, nil, sendios.PreferenceChange{Subscribe: []int{1, 2}}, "DELETE unsubtypes/all/1", []int{}},
+		{"both", []int{1, 2}, []int{1}, []int{3}, sendios.PreferenceChange{Unsubscribe: []int{3}, Subscribe: []int{1}}, "POST unsubtypes/1", []int{2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := sendiostest.NewServer()
+			defer server.Close()
+
+			sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", append(server.Options(), sendios.WithRetryPolicy(sendios.RetryPolicy{MaxAttempts: 1}))...)
+			user := server.AddUser(sendiostest.User{Email: "test@gmail.com", ProjectId: 2, UnsubscribedTypes: tt.unsubscribed})
+			ctx := context.Background()
+
+			preferences, err := sdk.PreferencesByEmailUserId(ctx, user.Id)
+			if err != nil {
+				t.Fatalf("PreferencesByEmailUserId() error = %v", err)
+			}
+			loaded := len(server.Requests())
+
+			change, err := sdk.UpdatePreferences(ctx, preferences, tt.subscribe, tt.unsubscribe)
+			if err != nil {
+				t.Fatalf("UpdatePreferences() error = %v", err)
+			}
+			if !reflect.DeepEqual(change, tt.wantChange) {
+				t.Errorf("UpdatePreferences() = %+v, want %+v", change, tt.wantChange)
+			}
+
+			requests := server.Requests()[loaded:]
+			var got []string
+			for _, request := range requests {
+				got = append(got, fmt.Sprintf("%s %s", request.Method, request.Route))
+			}
+			if (tt.wantRequest == "" && len(got) != 0) || (tt.wantRequest != "" && !reflect.DeepEqual(got, []string{tt.wantRequest})) {
+				t.Errorf("requests = %v, want %q", got, tt.wantRequest)
+			}
+
+			if ids := preferences.UnsubscribedTypeIds(); !reflect.DeepEqual(ids, tt.wantTypes) {
+				t.Errorf("UnsubscribedTypeIds() = %v, want %v", ids, tt.wantTypes)
+			}
+			reloaded, err := sdk.PreferencesByEmailUserId(ctx, user.Id)
+			if err != nil || !reflect.DeepEqual(reloaded.UnsubscribedTypeIds(), tt.wantTypes) {
+				t.Errorf("reloaded types = %v, %v, want %v", reloaded.UnsubscribedTypeIds(), err, tt.wantTypes)
+			}
+		})
+	}
+}
+
+func TestApplyPreferences_ConstructedPreferences(t *testing.T) {
+	server := sendiostest.NewServer()
+	defer server.Close()
+
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", server.Options()...)
+	user := server.AddUser(sendiostest.User{Email: "test@gmail.com", ProjectId: 2, UnsubscribedTypes: []int{1}})
+
+	preferences := &sendios.Preferences{UserId: user.Id, Unsubscribed: []sendios.UnsubType{{TypeId: 1}}}
+	change, err := sdk.ApplyPreferences(context.Background(), preferences, sendios.PreferenceChange{Unsubscribe: []int{2}})
+	if err != nil || !reflect.DeepEqual(change, sendios.PreferenceChange{Unsubscribe: []int{2}}) {
+		t.Fatalf("ApplyPreferences() = %+v, %v", change, err)
+	}
+	if ids := preferences.UnsubscribedTypeIds(); !reflect.DeepEqual(ids, []int{1, 2}) {
+		t.Errorf("UnsubscribedTypeIds() = %v", ids)
+	}
+}
+
+func TestPreferences_Conflict(t *testing.T) {
+	server := sendiostest.NewServer()
+	defer server.Close()
+
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", server.Options()...)
+	user := server.AddUser(sendiostest.User{Email: "test@gmail.com", ProjectId: 2})
+
+	preferences, err := sdk.PreferencesByEmailUserId(context.Background(), user.Id)
+	if err != nil {
+		t.Fatalf("PreferencesByEmailUserId() error = %v", err)
+	}
+	if _, err := sdk.UpdatePreferences(context.Background(), preferences, []int{1, 2}, []int{2}); !errors.Is(err, sendios.ErrConflictingPreferences) {
+		t.Errorf("UpdatePreferences() error = %v, want ErrConflictingPreferences", err)
+	}
+
+	server.FailNext("POST unsubtypes/nodiff/{user}", 1, http.StatusInternalServerError)
+	if _, err := sdk.UpdatePreferences(context.Background(), preferences, nil, []int{3}); !sendios.IsServerError(err) {
+		t.Errorf("UpdatePreferences() error = %v, want server error", err)
+	}
+	if preferences.IsUnsubscribed(3) {
+		t.Errorf("failed change was recorded")
+	}
+}
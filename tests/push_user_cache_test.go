@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	sendios "github.com/sendios/go-sdk"
+	"github.com/sendios/go-sdk/sendiostest"
+)
+
+func countPushLookups(server *sendiostest.Server) int {
+	count := 0
+	for _, request := range server.Requests() {
+		if strings.HasPrefix(request.Route, "webpush/user/get/") || strings.HasPrefix(request.Route, "webpush/project/get/") {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestPushUserCache(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []sendios.Option
+		wantLookups int
+	}{
+		{"default_cache", nil, 2},
+		{"disabled", []sendios.Option{sendios.WithPushUserCache(0, 0, 0)}, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := sendiostest.NewServer()
+			defer server.Close()
+			pushUser := server.AddPushUser(sendiostest.PushUser{UserId: 5005, ProjectId: 2, Hash: "hash"})
+
+			sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", append(server.Options(), tt.opts...)...)
+
+			calls := []func() ([]byte, error){
+				func() ([]byte, error) {
+					return sdk.SendPushByEmailUserId(5005, "title", "text", "url", "icon", 1, nil, "")
+				},
+				func() ([]byte, error) { return sdk.UnsubscribePushUserByEmailUserId(5005) },
+				func() ([]byte, error) { return sdk.SubscribePushUserByEmailUserId(5005) },
+				func() ([]byte, error) {
+					return sdk.SendPushByProjectIdAndHash(2, "hash", "title", "text", "url", "icon", 1, nil, "")
+				},
+				func() ([]byte, error) { return sdk.UnsubscribePushUserByProjectIdAndHash(2, "hash") },
+				func() ([]byte, error) { return sdk.SubscribePushUserByProjectIdAndHash(2, "hash") },
+			}
+			for i, call := range calls {
+				if _, err := call(); err != nil {
+					t.Fatalf("call %d error = %v", i, err)
+				}
+			}
+
+			if got := countPushLookups(server); got != tt.wantLookups {
+				t.Errorf("lookups = %d, want %d", got, tt.wantLookups)
+			}
+			if pushes := server.SentPushes(); len(pushes) != 2 || pushes[0].PushUserId != pushUser.Id || pushes[1].PushUserId != pushUser.Id {
+				t.Errorf("pushes = %+v", pushes)
+			}
+		})
+	}
+}
+
+func TestPushUserCache_Negative(t *testing.T) {
+	server := sendiostest.NewServer()
+	defer server.Close()
+
+	options := append(server.Options(), sendios.WithPushUserCache(10, time.Minute, 50*time.Millisecond))
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", options...)
+
+	for i := 0; i < 2; i++ {
+		if _, err := sdk.SendPushByEmailUserId(5005, "title", "text", "url", "icon", 1, nil, ""); !sendios.IsNotFound(err) {
+			t.Fatalf("SendPushByEmailUserId() for unknown push user error = %v", err)
+		}
+	}
+	if got := countPushLookups(server); got != 1 {
+		t.Errorf("lookups = %d, want the not found result to be cached", got)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	server.AddPushUser(sendiostest.PushUser{UserId: 5005, ProjectId: 2, Hash: "hash"})
+	if _, err := sdk.SendPushByEmailUserId(5005, "title", "text", "url", "icon", 1, nil, ""); err != nil {
+		t.Fatalf("SendPushByEmailUserId() after negative ttl error = %v", err)
+	}
+}
+
+func TestPushUserCache_CreatePushUser(t *testing.T) {
+	server := sendiostest.NewServer()
+	defer server.Close()
+
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", server.Options()...)
+
+	if _, err := sdk.SendPushByEmailUserId(5005, "title", "text", "url", "icon", 1, nil, ""); !sendios.IsNotFound(err) {
+		t.Fatalf("SendPushByEmailUserId() for unknown push user error = %v", err)
+	}
+	if _, err := sdk.CreatePushUser(5005, 2, "https://push.example.com", "key", "token"); err != nil {
+		t.Fatalf("CreatePushUser() error = %v", err)
+	}
+	if _, err := sdk.SendPushByEmailUserId(5005, "title", "text", "url", "icon", 1, nil, ""); err != nil {
+		t.Errorf("SendPushByEmailUserId() after CreatePushUser() error = %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sendios "github.com/sendios/go-sdk"
+	"github.com/sendios/go-sdk/internal"
+)
+
+func TestRateLimit(t *testing.T) {
+	var userId int32
+
+	tests := []struct {
+		name        string
+		opts        []sendios.Option
+		call        func(sdk *sendios.SendiosSdk) ([]byte, error)
+		calls       int
+		minDuration time.Duration
+		maxDuration time.Duration
+	}{
+		{"global_limit", []sendios.Option{sendios.WithRateLimit(50, 1)},
+			func(sdk *sendios.SendiosSdk) ([]byte, error) {
+				return sdk.SetUserFieldsByUserId(1, map[string]string{})
+			},
+			5, 80 * time.Millisecond, time.Second},
+		{"burst_is_not_delayed", []sendios.Option{sendios.WithRateLimit(1, 5)},
+			func(sdk *sendios.SendiosSdk) ([]byte, error) {
+				return sdk.SetUserFieldsByUserId(1, map[string]string{})
+			},
+			5, 0, 500 * time.Millisecond},
+		{"route_limit", []sendios.Option{sendios.WithRouteRateLimit("webpush/send", 50, 1)},
+			func(sdk *sendios.SendiosSdk) ([]byte, error) {
+				return sdk.SendPushByProject(2, "title", "text", "url", "icon", 1, nil, "image")
+			},
+			5, 80 * time.Millisecond, time.Second},
+		{"route_limit_does_not_apply_to_other_routes", []sendios.Option{sendios.WithRouteRateLimit("webpush/send", 1, 1)},
+			func(sdk *sendios.SendiosSdk) ([]byte, error) {
+				return sdk.SetUserFieldsByUserId(1, map[string]string{})
+			},
+			5, 0, 500 * time.Millisecond},
+		{"route_limit_matches_sub_routes", []sendios.Option{sendios.WithRouteRateLimit("userfields", 50, 1)},
+			func(sdk *sendios.SendiosSdk) ([]byte, error) {
+				return sdk.SetUserFieldsByUserId(1, map[string]string{})
+			},
+			5, 80 * time.Millisecond, time.Second},
+		{"route_limit_matches_route_template", []sendios.Option{sendios.WithRouteRateLimit("unsub/isunsub/%d", 50, 1)},
+			func(sdk *sendios.SendiosSdk) ([]byte, error) {
+				return sdk.IsUnsubUser(int(atomic.AddInt32(&userId, 1)))
+			},
+			5, 80 * time.Millisecond, time.Second},
+		{"zero_rate_is_no_limit", []sendios.Option{sendios.WithRateLimit(0, 1), sendios.WithRouteRateLimit("userfields", 0, 1)},
+			func(sdk *sendios.SendiosSdk) ([]byte, error) {
+				return sdk.SetUserFieldsByUserId(1, map[string]string{})
+			},
+			5, 0, 500 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"_meta":{"status":"SUCCESS"},"data":null}`)
+			}))
+			defer ts.Close()
+
+			sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", append(tt.opts, sendios.WithApiV1BaseUrl(ts.URL))...)
+
+			start := time.Now()
+			for i := 0; i < tt.calls; i++ {
+				if _, err := tt.call(sdk); err != nil {
+					t.Fatalf("expected error to be nil got %v", err)
+				}
+			}
+			elapsed := time.Since(start)
+
+			if elapsed < tt.minDuration || elapsed > tt.maxDuration {
+				t.Errorf("%d calls took %v, want between %v and %v", tt.calls, elapsed, tt.minDuration, tt.maxDuration)
+			}
+		})
+	}
+}
+
+func TestRateLimit_ContextAware(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		fmt.Fprint(w, `{"_meta":{"status":"SUCCESS"},"data":null}`)
+	}))
+	defer ts.Close()
+
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", sendios.WithApiV1BaseUrl(ts.URL), sendios.WithRateLimit(1, 1))
+
+	if _, err := sdk.IsUnsubUser(1); err != nil {
+		t.Fatalf("expected error to be nil got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := sdk.IsUnsubUserCtx(ctx, 1)
+	if !errors.Is(err, sendios.ErrRateLimitDeadline) {
+		t.Errorf("expected rate limit deadline error, got %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	_, err = sdk.IsUnsubUserCtx(ctx, 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled error, got %v", err)
+	}
+
+	if requests != 1 {
+		t.Errorf("requests = %v, want 1", requests)
+	}
+}
+
+func TestRateLimiter_RefundsRouteTokenWhenGlobalWaitFails(t *testing.T) {
+	limiter := &internal.RateLimiter{
+		Global: internal.NewTokenBucket(1, 1),
+		Routes: map[string]*internal.TokenBucket{"webpush/send": internal.NewTokenBucket(1, 2)},
+	}
+
+	if err := limiter.Wait(context.Background(), "webpush/send"); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx, "webpush/send"); !errors.Is(err, internal.ErrRateLimitDeadline) {
+		t.Fatalf("Wait() error = %v, want ErrRateLimitDeadline", err)
+	}
+
+	// the route bucket still holds the token not used by the failed wait
+	limiter.Global = nil
+	if err := limiter.Wait(ctx, "webpush/send"); err != nil {
+		t.Errorf("Wait() after failed global wait error = %v", err)
+	}
+}
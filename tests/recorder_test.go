@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sendios "github.com/sendios/go-sdk"
+	"github.com/sendios/go-sdk/internal"
+	"github.com/sendios/go-sdk/sendiostest"
+)
+
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recorder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cassette := filepath.Join(dir, "cassette.json")
+
+	const authKey = "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"
+	ctx := context.Background()
+
+	server := sendiostest.NewServer()
+	user := server.AddUser(sendiostest.User{Email: "secret.person@gmail.com", ProjectId: 2, Name: "Volodymyr"})
+	server.UnsubTypeNames = map[int]string{7: "News"}
+
+	recorder, err := sendiostest.NewRecorder(cassette, sendiostest.ModeRecord)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	options := append(server.Options(), sendios.WithTransport(recorder))
+	sdk := sendios.NewSendiosSdk("3", authKey, options...)
+
+	if _, err := sdk.UnsubEmailUserByAdmin("secret.person@gmail.com", 2); err != nil {
+		t.Fatalf("UnsubEmailUserByAdmin() error = %v", err)
+	}
+	recorded, err := sdk.EmailUserByEmailAndProjectId(ctx, "secret.person@gmail.com", 2)
+	if err != nil {
+		t.Fatalf("EmailUserByEmailAndProjectId() error = %v", err)
+	}
+	if _, err := sdk.SendEmail(1, 3, sendios.System, 2, "secret.person@gmail.com", nil, map[string]string{"a": "b"}, nil); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	if _, err := sdk.CreatePushUser(user.Id, 2, "https://push.example.com/endpoint", "secret-public-key", "secret-auth-token"); err != nil {
+		t.Fatalf("CreatePushUser() error = %v", err)
+	}
+	if err := recorder.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	server.Close()
+
+	content, err := ioutil.ReadFile(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secrets := []string{
+		"secret.person@gmail.com",
+		"c2VjcmV0LnBlcnNvbkBnbWFpbC5jb20",
+		"secret-public-key",
+		"secret-auth-token",
+		internal.Sha1Encoder(authKey),
+	}
+	for _, secret := range secrets {
+		if strings.Contains(string(content), secret) {
+			t.Errorf("cassette contains %q:\n%s", secret, content)
+		}
+	}
+
+	replayer, err := sendiostest.NewRecorder(cassette, sendiostest.ModeReplay)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	options = append(server.Options(), sendios.WithTransport(replayer))
+	sdk = sendios.NewSendiosSdk("3", authKey, options...)
+
+	if _, err := sdk.UnsubEmailUserByAdmin("secret.person@gmail.com", 2); err != nil {
+		t.Errorf("replayed UnsubEmailUserByAdmin() error = %v", err)
+	}
+	replayed, err := sdk.EmailUserByEmailAndProjectId(ctx, "secret.person@gmail.com", 2)
+	if err != nil || replayed.Id != user.Id || replayed.Id != recorded.Id || replayed.Name != "Volodymyr" {
+		t.Errorf("replayed EmailUserByEmailAndProjectId() = %+v, %v", replayed, err)
+	}
+	if _, err := sdk.SendEmail(1, 3, sendios.System, 2, "secret.person@gmail.com", nil, map[string]string{"a": "b"}, nil); err != nil {
+		t.Errorf("replayed SendEmail() error = %v", err)
+	}
+	if _, err := sdk.CreatePushUser(user.Id, 2, "https://push.example.com/endpoint", "other-public-key", "other-auth-token"); err != nil {
+		t.Errorf("replayed CreatePushUser() error = %v", err)
+	}
+
+	_, err = sdk.UnsubEmailUserByAdmin("someone.else@gmail.com", 2)
+	if !errors.Is(err, sendiostest.ErrNoInteraction) {
+		t.Errorf("UnsubEmailUserByAdmin() for unrecorded email error = %v, want ErrNoInteraction", err)
+	}
+}
+
+func TestRecorder_RedactsEncodedEmailsInBodies(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recorder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cassette := filepath.Join(dir, "cassette.json")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"_meta":{"status":"SUCCESS"},"data":{"encoded":"dXNlcj8/QG1haWwub3Jn"}}`)
+	}))
+	defer server.Close()
+
+	recorder, err := sendiostest.NewRecorder(cassette, sendiostest.ModeRecord)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	client := &http.Client{Transport: recorder}
+	response, err := client.Post(server.URL+"/user", "application/json", strings.NewReader(`{"email":"c2VjcmV0LnBlcnNvbkBnbWFpbC5jb20="}`))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	response.Body.Close()
+	if err := recorder.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	content, err := ioutil.ReadFile(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"c2VjcmV0LnBlcnNvbkBnbWFpbC5jb20", "dXNlcj8/QG1haWwub3Jn"} {
+		if strings.Contains(string(content), secret) {
+			t.Errorf("cassette contains %q:\n%s", secret, content)
+		}
+	}
+}
+
+func TestRecorder_RedactsEncodedEmailsInRoutes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recorder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cassette := filepath.Join(dir, "cassette.json")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"_meta":{"status":"SUCCESS"},"data":null}`)
+	}))
+	defer server.Close()
+
+	recorder, err := sendiostest.NewRecorder(cassette, sendiostest.ModeRecord)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	client := &http.Client{Transport: recorder}
+	for _, route := range []string{"/user/project/1/email/secret.person%40gmail.com", "/user/email/c2VjcmV0LnBlcnNvbkBnbWFpbC5jb20="} {
+		response, err := client.Get(server.URL + route)
+		if err != nil {
+			t.Fatalf("Get(%v) error = %v", route, err)
+		}
+		response.Body.Close()
+	}
+	if err := recorder.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	content, err := ioutil.ReadFile(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"secret.person", "c2VjcmV0LnBlcnNvbkBnbWFpbC5jb20"} {
+		if strings.Contains(string(content), secret) {
+			t.Errorf("cassette contains %q:\n%s", secret, content)
+		}
+	}
+}
+
+func TestRecorder_MissingCassette(t *testing.T) {
+	if _, err := sendiostest.NewRecorder(filepath.Join(os.TempDir(), "missing-cassette.json"), sendiostest.ModeReplay); err == nil {
+		t.Error("NewRecorder() for a missing cassette error = nil")
+	}
+}
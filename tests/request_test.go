@@ -1 +1,96 @@
 package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sendios/go-sdk/internal"
+)
+
+func TestRequest_ContextCancellation(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+	}{
+		{"post", http.MethodPost},
+		{"get", http.MethodGet},
+		{"put", http.MethodPut},
+		{"delete", http.MethodDelete},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				<-release
+			}))
+			defer ts.Close()
+			defer close(release)
+
+			r := &internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			err := r.Do(ctx, tt.method, ts.URL, "/route", nil, nil)
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected deadline exceeded error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRequest_Do(t *testing.T) {
+	type result struct {
+		Data struct {
+			Status bool `json:"status"`
+		} `json:"data"`
+	}
+
+	var gotQuery url.Values
+	var gotHeader http.Header
+	var gotBody []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query()
+		gotHeader = r.Header
+		gotBody, _ = ioutil.ReadAll(r.Body)
+		fmt.Fprintln(w, `{"_meta": {"status": "SUCCESS"}, "data": {"status": true}}`)
+	}))
+	defer ts.Close()
+
+	r := &internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}
+	ctx := context.Background()
+
+	var typed result
+	err := r.Do(ctx, http.MethodPost, ts.URL, "/route", map[string]int{"id": 1}, &typed,
+		internal.WithQuery(url.Values{"page": {"2"}}), internal.WithHeader("X-Trace", "abc"))
+	if err != nil || !typed.Data.Status {
+		t.Fatalf("Do() = %+v, %v", typed, err)
+	}
+	if gotQuery.Get("page") != "2" || gotHeader.Get("X-Trace") != "abc" || string(gotBody) != `{"id":1}` {
+		t.Errorf("request query = %v, header = %v, body = %s", gotQuery, gotHeader, gotBody)
+	}
+
+	var raw []byte
+	if err := r.Do(ctx, http.MethodGet, ts.URL, "/route", nil, &raw); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if string(raw) != `{"_meta":{"status":"SUCCESS"},"data":{"status":true}}` {
+		t.Errorf("raw = %s", raw)
+	}
+	if len(gotBody) != 0 {
+		t.Errorf("GET body = %s, want none", gotBody)
+	}
+
+	err = r.Do(ctx, http.MethodPost, ts.URL, "/route", map[string]interface{}{"bad": make(chan int)}, nil)
+	if err == nil || !strings.Contains(err.Error(), "error while marshaling request data") {
+		t.Errorf("Do() with unmarshalable body error = %v", err)
+	}
+}
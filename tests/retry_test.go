@@ -0,0 +1,203 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sendios "github.com/sendios/go-sdk"
+	"github.com/sendios/go-sdk/internal"
+)
+
+func TestRequest_Retry(t *testing.T) {
+	policy := sendios.RetryPolicy{
+		MaxAttempts:       3,
+		InitialBackoff:    time.Millisecond,
+		MaxBackoff:        5 * time.Millisecond,
+		Multiplier:        2,
+		RetryableStatuses: []int{http.StatusTooManyRequests, http.StatusServiceUnavailable},
+	}
+
+	tests := []struct {
+		name           string
+		failures       int32
+		failStatus     int
+		call           func(sdk *sendios.SendiosSdk) ([]byte, error)
+		wantAttempts   int32
+		wantErr        bool
+		wantIdempotent bool
+	}{
+		{"get_retried_until_success", 2, http.StatusServiceUnavailable,
+			func(sdk *sendios.SendiosSdk) ([]byte, error) { return sdk.GetEmailUserById(1) }, 3, false, false},
+		{"get_gives_up_after_max_attempts", 5, http.StatusServiceUnavailable,
+			func(sdk *sendios.SendiosSdk) ([]byte, error) { return sdk.GetEmailUserById(1) }, 3, true, false},
+		{"put_retried_on_rate_limit", 1, http.StatusTooManyRequests,
+			func(sdk *sendios.SendiosSdk) ([]byte, error) {
+				return sdk.SetUserFieldsByUserId(1, map[string]string{"a": "b"})
+			}, 2, false, false},
+		{"delete_retried", 1, http.StatusServiceUnavailable,
+			func(sdk *sendios.SendiosSdk) ([]byte, error) { return sdk.SubscribeEmailUser(1) }, 2, false, false},
+		{"non_retryable_status", 1, http.StatusNotFound,
+			func(sdk *sendios.SendiosSdk) ([]byte, error) { return sdk.GetEmailUserById(1) }, 1, true, false},
+		{"post_without_idempotency_key", 1, http.StatusServiceUnavailable,
+			func(sdk *sendios.SendiosSdk) ([]byte, error) { return sdk.TrackClickByMailId(1) }, 1, true, false},
+		{"post_with_idempotency_key", 1, http.StatusServiceUnavailable,
+			func(sdk *sendios.SendiosSdk) ([]byte, error) {
+				ctx := sendios.ContextWithIdempotencyKey(context.Background(), "send-1")
+				return sdk.SendEmailCtx(ctx, 3, 1, sendios.System, 2, "test@gmail.com", map[string]string{}, map[string]string{}, nil)
+			}, 2, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			var idempotencyKeys []string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				idempotencyKeys = append(idempotencyKeys, r.Header.Get("Idempotency-Key"))
+				if atomic.AddInt32(&attempts, 1) <= tt.failures {
+					w.WriteHeader(tt.failStatus)
+					fmt.Fprint(w, `{"_meta":{"status":"ERROR"},"data":{"error":"try again"}}`)
+					return
+				}
+				fmt.Fprint(w, `{"_meta":{"status":"SUCCESS"},"data":null}`)
+			}))
+			defer ts.Close()
+
+			sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6",
+				sendios.WithApiV1BaseUrl(ts.URL),
+				sendios.WithEncryptionKey("0123456789abcdef"),
+				sendios.WithRetryPolicy(policy),
+			)
+
+			_, err := tt.call(sdk)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %v, want %v", attempts, tt.wantAttempts)
+			}
+			for _, key := range idempotencyKeys {
+				if (key != "") != tt.wantIdempotent {
+					t.Errorf("unexpected Idempotency-Key header %q", key)
+				}
+			}
+		})
+	}
+}
+
+func TestRequest_RetryOnNetworkError(t *testing.T) {
+	var attempts int32
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			return nil, errors.New("connection reset by peer")
+		}
+		return http.DefaultTransport.RoundTrip(r)
+	})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"_meta":{"status":"SUCCESS"},"data":null}`)
+	}))
+	defer ts.Close()
+
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6",
+		sendios.WithApiV1BaseUrl(ts.URL),
+		sendios.WithTransport(transport),
+		sendios.WithRetryPolicy(sendios.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}),
+	)
+
+	if _, err := sdk.IsUnsubUser(1); err != nil {
+		t.Errorf("expected error to be nil got %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("attempts = %v, want 2", attempts)
+	}
+}
+
+func TestRequest_RetryHonoursRetryAfter(t *testing.T) {
+	var attempts int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `{"_meta":{"status":"SUCCESS"},"data":null}`)
+	}))
+	defer ts.Close()
+
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6",
+		sendios.WithApiV1BaseUrl(ts.URL),
+		sendios.WithRetryPolicy(sendios.DefaultRetryPolicy()),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := sdk.IsUnsubUserCtx(ctx, 1)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected retry to wait for Retry-After and hit the deadline, got %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %v, want 1", attempts)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := internal.RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+
+	tests := []struct {
+		name       string
+		attempt    int
+		retryAfter time.Duration
+		want       time.Duration
+	}{
+		{"first", 1, 0, 100 * time.Millisecond},
+		{"second", 2, 0, 200 * time.Millisecond},
+		{"third", 3, 0, 400 * time.Millisecond},
+		{"capped", 10, 0, time.Second},
+		{"retry_after", 1, 500 * time.Millisecond, 500 * time.Millisecond},
+		{"retry_after_capped", 1, 3 * time.Second, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Backoff(tt.attempt, tt.retryAfter); got != tt.want {
+				t.Errorf("Backoff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	jittered := internal.RetryPolicy{InitialBackoff: 100 * time.Millisecond, Multiplier: 2, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		if got := jittered.Backoff(1, 0); got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("Backoff() with jitter = %v, want within [50ms, 150ms]", got)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2021, 7, 5, 13, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{"empty", "", 0},
+		{"seconds", "120", 2 * time.Minute},
+		{"negative", "-1", 0},
+		{"http_date", "Mon, 05 Jul 2021 13:00:30 GMT", 30 * time.Second},
+		{"past_date", "Mon, 05 Jul 2021 12:00:00 GMT", 0},
+		{"garbage", "soon", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := internal.ParseRetryAfter(tt.value, now); got != tt.want {
+				t.Errorf("ParseRetryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
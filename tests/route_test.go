@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	sendios "github.com/sendios/go-sdk"
+	"github.com/sendios/go-sdk/internal"
+)
+
+func TestRoutes_TrickyAddresses(t *testing.T) {
+	emails := []string{
+		"a+b@x.com",
+		"???@x.com",
+		"test~~~>@gmail.com",
+		"o'neil+tag@x.com",
+		"a/b@x.com",
+		"a%40b@x.com",
+		"a#b@x.com",
+		"a b@x.com",
+		"üser@exämple.com",
+	}
+	methods := []struct {
+		name    string
+		base64  bool
+		segment int
+		call    func(sdk *sendios.SendiosSdk, email string) error
+	}{
+		{"GetEmailUserByEmailAndProjectId", false, 5, func(sdk *sendios.SendiosSdk, email string) error {
+			_, err := sdk.GetEmailUserByEmailAndProjectId(email, 2)
+			return err
+		}},
+		{"GetUserFieldsByEmailAndProjectId", false, 5, func(sdk *sendios.SendiosSdk, email string) error {
+			_, err := sdk.GetUserFieldsByEmailAndProjectId(email, 2)
+			return err
+		}},
+		{"UnsubEmailUserByAdmin", true, 5, func(sdk *sendios.SendiosSdk, email string) error {
+			_, err := sdk.UnsubEmailUserByAdmin(email, 2)
+			return err
+		}},
+		{"SetUserFieldsByEmailAndProjectId", true, 5, func(sdk *sendios.SendiosSdk, email string) error {
+			_, err := sdk.SetUserFieldsByEmailAndProjectId(email, 2, map[string]string{"plan": "gold"})
+			return err
+		}},
+		{"SetOnlineByEmailAndProjectId", true, 5, func(sdk *sendios.SendiosSdk, email string) error {
+			_, err := sdk.SetOnlineByEmailAndProjectId(email, 2)
+			return err
+		}},
+		{"ForceConfirmByEmailAndProject", true, 5, func(sdk *sendios.SendiosSdk, email string) error {
+			_, err := sdk.ForceConfirmByEmailAndProject(email, 2)
+			return err
+		}},
+		{"GetPushUserByProjectIdAndHash", false, 6, func(sdk *sendios.SendiosSdk, email string) error {
+			_, err := sdk.GetPushUserByProjectIdAndHash(2, email)
+			return err
+		}},
+	}
+	for _, method := range methods {
+		for _, email := range emails {
+			t.Run(fmt.Sprintf("%s/%s", method.name, email), func(t *testing.T) {
+				var escapedPath string
+				ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					escapedPath = r.URL.EscapedPath()
+					fmt.Fprint(w, `{"_meta":{"status":"SUCCESS"},"data":{"status":true}}`)
+				}))
+				defer ts.Close()
+
+				sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6",
+					sendios.WithApiV1BaseUrl(ts.URL+"/v1"), sendios.WithApiV3BaseUrl(ts.URL+"/v3"))
+				if err := method.call(sdk, email); err != nil {
+					t.Fatalf("error = %v", err)
+				}
+
+				segments := strings.Split(strings.TrimPrefix(escapedPath, "/"), "/")
+				if len(segments) <= method.segment {
+					t.Fatalf("path %s has too few segments", escapedPath)
+				}
+				if strings.Contains(segments[method.segment], "+") {
+					t.Errorf("segment %s contains an unescaped +", segments[method.segment])
+				}
+
+				got, err := url.PathUnescape(segments[method.segment])
+				if err != nil {
+					t.Fatalf("PathUnescape(%s) error = %v", segments[method.segment], err)
+				}
+				if method.base64 {
+					decoded, err := base64.StdEncoding.DecodeString(got)
+					if err != nil {
+						t.Fatalf("base64 segment %s error = %v", got, err)
+					}
+					got = string(decoded)
+				}
+				if got != email {
+					t.Errorf("path %s carries %q, want %q", escapedPath, got, email)
+				}
+			})
+		}
+	}
+}
+
+func TestEscapePathSegment(t *testing.T) {
+	tests := []struct {
+		segment string
+		want    string
+	}{
+		{"a+b@x.com", "a%2Bb@x.com"},
+		{"Pz8/QHguY29t", "Pz8%2FQHguY29t"},
+		{"a b?#%", "a%20b%3F%23%25"},
+		{"plain", "plain"},
+	}
+	for _, tt := range tests {
+		if got := internal.EscapePathSegment(tt.segment); got != tt.want {
+			t.Errorf("EscapePathSegment(%q) = %q, want %q", tt.segment, got, tt.want)
+		}
+	}
+}
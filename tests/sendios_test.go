@@ -1,17 +1,20 @@
 package tests
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	sendios "github.com/sendios/go-sdk"
+	"github.com/sendios/go-sdk/internal"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
-	"sendios"
-	"sendios/internal"
 	"testing"
 	"time"
 )
 
 func TestNewSendiosSdk(t *testing.T) {
+	defaultRetryPolicy := sendios.DefaultRetryPolicy()
 	type args struct {
 		clientId string
 		authKey  string
@@ -19,17 +22,16 @@ func TestNewSendiosSdk(t *testing.T) {
 	tests := []struct {
 		name string
 		args args
-		want *sendios.SendiosSdk
+		want *internal.Request
 	}{
 		{
 			"new_sendios_object",
 			args{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"},
-			&sendios.SendiosSdk{
-				&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}}},
+			&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}, Retry: &defaultRetryPolicy, Metrics: sendios.NewMetrics()}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := sendios.NewSendiosSdk(tt.args.clientId, tt.args.authKey); !reflect.DeepEqual(got, tt.want) {
+			if got := sendios.NewSendiosSdk(tt.args.clientId, tt.args.authKey).Request; !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("NewSendiosSdk() = %v, want %v", got, tt.want)
 			}
 		})
@@ -57,7 +59,7 @@ func TestSendiosSdk_AddPaymentByEmailAndProjectId(t *testing.T) {
 		wantErr bool
 	}{
 		{"add_payment_by_email",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{"test@gmail.com", 1, 1625479419, 1625479419, 1, 1, 1},
 			[]byte(`{"_meta":{"count":3,"status":"SUCCESS","time":3701},"data":{"date":"2021-07-05 13:03:39.000000","message":"done","status":true}}`),
 			true},
@@ -84,7 +86,9 @@ func TestSendiosSdk_AddPaymentByEmailAndProjectId(t *testing.T) {
 				Amount:      tt.args.amount,
 			}
 
-			got, err := sdk.Request.Post(ts.URL, "/lastpayment", params)
+			var got []byte
+
+			err := sdk.Request.Do(context.Background(), http.MethodPost, ts.URL, "/lastpayment", params, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -116,7 +120,7 @@ func TestSendiosSdk_AddPaymentByUserId(t *testing.T) {
 		wantErr bool
 	}{
 		{"add_payment_by_user_id",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{1, 1625479419, 1625479419, 1, 1, 1},
 			[]byte(`{"_meta":{"count":3,"status":"SUCCESS","time":3964},"data":{"date":"2021-07-05 13:57:57.000000","message":"done","status":true}}`),
 			true},
@@ -143,7 +147,9 @@ func TestSendiosSdk_AddPaymentByUserId(t *testing.T) {
 				Amount:      tt.args.amount,
 			}
 
-			got, err := sdk.Request.Post(ts.URL, "/lastpayment", params)
+			var got []byte
+
+			err := sdk.Request.Do(context.Background(), http.MethodPost, ts.URL, "/lastpayment", params, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -171,7 +177,7 @@ func TestSendiosSdk_AddTypesToUnsubByEmailUser(t *testing.T) {
 		wantErr bool
 	}{
 		{"add_types_to_unsub_by_email",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{1, []int{1, 2, 3, 4, 5}},
 			[]byte(`{"_meta":{"count":1,"status":"SUCCESS","time":3665},"data":null}`),
 			true},
@@ -190,7 +196,8 @@ func TestSendiosSdk_AddTypesToUnsubByEmailUser(t *testing.T) {
 			defer ts.Close()
 
 			params := internal.TypeIds{TypeIds: tt.args.typeIds}
-			got, err := sdk.Request.Post(ts.URL, "/unsubtypes/nodiff", params)
+			var got []byte
+			err := sdk.Request.Do(context.Background(), http.MethodPost, ts.URL, "/unsubtypes/nodiff", params, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -219,37 +226,37 @@ func TestSendiosSdk_CheckEmail(t *testing.T) {
 	}{
 
 		{"check_email_invalid",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{"test@gmail.com", true},
 			[]byte(`{"_meta":{"count":7,"status":"SUCCESS","time":3499},"data":{"domain":"gmail.com","email":"test@gmail.com","orig":"test@gmail.com","reason":"system","trusted":true,"valid":false,"vendor":"Google"}}`),
 			true},
 
 		{"check_email_valid",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{"volodymyr.voloshyn@corp.sendios.io", true},
 			[]byte(`{"_meta":{"count":6,"status":"SUCCESS","time":4449},"data":{"domain":"corp.sendios.io","email":"volodymyr.voloshyn@corp.sendios.io","orig":"volodymyr.voloshyn@corp.sendios.io","trusted":true,"valid":true,"vendor":"Unknown"}}`),
 			true},
 
 		{"check_email_invalid_sanitize_false",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{"test@gmail.com", false},
 			[]byte(`{"_meta":{"count":7,"status":"SUCCESS","time":3962},"data":{"domain":"gmail.com","email":"test@gmail.com","orig":"test@gmail.com","reason":"system","trusted":true,"valid":false,"vendor":"Google"}}`),
 			true},
 
 		{"check_email_valid_sanitize_false",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{"volodymyr.voloshyn@corp.sendios.io", false},
 			[]byte(`{"_meta":{"count":6,"status":"SUCCESS","time":4941},"data":{"domain":"corp.sendios.io","email":"volodymyr.voloshyn@corp.sendios.io","orig":"volodymyr.voloshyn@corp.sendios.io","trusted":true,"valid":true,"vendor":"Unknown"}}`),
 			true},
 
 		{"check_email_valid_untrusted_sanitize_false",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{"test.com", false},
 			[]byte(`{"_meta":{"count":7,"status":"SUCCESS","time":3741},"data":{"domain":"test.com","email":"test.com","orig":"test.com","reason":"invalid","trusted":false,"valid":false,"vendor":"Unknown"}}`),
 			true},
 
 		{"check_email_valid_untrusted_sanitize_true",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{"test.com", true},
 			[]byte(`{"_meta":{"count":7,"status":"SUCCESS","time":4070},"data":{"domain":"test.com","email":"test.com@test.com","orig":"test.com","reason":"mx_record","trusted":false,"valid":false,"vendor":"Unknown"}}`),
 			true},
@@ -268,7 +275,8 @@ func TestSendiosSdk_CheckEmail(t *testing.T) {
 			defer ts.Close()
 
 			params := internal.CheckEmail{Email: tt.args.email, Sanitize: tt.args.sanitize}
-			got, err := sdk.Request.Post(ts.URL, "/email/check", params)
+			var got []byte
+			err := sdk.Request.Do(context.Background(), http.MethodPost, ts.URL, "/email/check", params, &got)
 
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
@@ -298,13 +306,13 @@ func TestSendiosSdk_CreateClientUser(t *testing.T) {
 		wantErr bool
 	}{
 		{"create_client_user",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{"test@gmail.com", "1", 2},
 			[]byte(`{"_meta":{"count":3,"status":"SUCCESS","time":4301},"data":{"date":"2021-07-05 15:45:39.000000","message":"done","status":true}}`),
 			true},
 
 		{"create_client_user_already_exist",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{"test@gmail.com", "1", 2},
 			[]byte(`{"_meta":{"count":3,"status":"SUCCESS","time":3414},"data":{"date":"2021-07-05 15:51:09.000000","message":"done","status":true}}`),
 			true},
@@ -323,7 +331,8 @@ func TestSendiosSdk_CreateClientUser(t *testing.T) {
 			defer ts.Close()
 
 			params := internal.ClientUser{Email: tt.args.email, ClientUserId: tt.args.clientUserId, ProjectId: tt.args.projectId}
-			got, err := sdk.Request.Post(ts.URL, "/clientuser/create", params)
+			var got []byte
+			err := sdk.Request.Do(context.Background(), http.MethodPost, ts.URL, "/clientuser/create", params, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -389,13 +398,13 @@ func TestSendiosSdk_ForceConfirmByEmailAndProject(t *testing.T) {
 		wantErr bool
 	}{
 		{"force_confirm_by_email",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{"test@gmail.com", 2},
 			[]byte(`{"status":"Accepted"}`),
 			true},
 
 		{"force_confirm_by_invalid_email",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{"testgmail.com", 2},
 			[]byte(`{"status":"Accepted"}`),
 			true},
@@ -421,7 +430,9 @@ func TestSendiosSdk_ForceConfirmByEmailAndProject(t *testing.T) {
 				LastReaction: time.Now().Unix(),
 			}
 
-			got, err := sdk.Request.Put(ts.URL, fmt.Sprintf("/users/project/%d/email/%s/confirm", tt.args.projectId, encodedEmail), params)
+			var got []byte
+
+			err := sdk.Request.Do(context.Background(), http.MethodPut, ts.URL, fmt.Sprintf("/users/project/%d/email/%s/confirm", tt.args.projectId, encodedEmail), params, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -447,13 +458,13 @@ func TestSendiosSdk_GetBuyingDecisions(t *testing.T) {
 		wantErr bool
 	}{
 		{"buying_decision",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{"test@gmail.com"},
 			[]byte(`{"_meta":{"count":2,"status":"SUCCESS","time":3923},"data":{"decision":false,"email":"test@gmail.com"}}`),
 			true},
 
 		{"buying_decision_validation_error",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{"testgmail.com"},
 			[]byte(`{"_meta":{"count":1,"status":"ERROR","time":3407},"data":{"error":"Request validation error:  email - This value is not a valid email address."}}`),
 			true},
@@ -472,7 +483,8 @@ func TestSendiosSdk_GetBuyingDecisions(t *testing.T) {
 			defer ts.Close()
 
 			params := internal.BuyingDecisionData{Email: tt.args.email}
-			got, err := sdk.Request.Post(ts.URL, "/buying/email", params)
+			var got []byte
+			err := sdk.Request.Do(context.Background(), http.MethodPost, ts.URL, "/buying/email", params, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -500,13 +512,13 @@ func TestSendiosSdk_GetEmailUserByEmailAndProjectId(t *testing.T) {
 		wantErr bool
 	}{
 		{"email_user_by_email_and_project",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{"volodymyr.voloshyn@corp.sendios.io", 2},
 			[]byte(`{"_meta":{"count":1,"status":"SUCCESS","time":3668},"data":{"user":{"activation":null,"channel_id":null,"clicks":0,"country":false,"created_at":"2021-06-17 10:29:27","email":"volodymyr.voloshyn@corp.sendios.io","err_response":0,"gender":"m","id":5005,"language":"en","last_mailed":null,"last_online":null,"last_payment":{"active":1,"amount":1,"expires_at":1625479419,"id":1,"payment_count":1,"payment_type":1,"project_id":2,"started_at":1625479419,"user_id":5005},"last_reaction":null,"last_request":null,"meta":{"profile":{"age":null,"ak":null,"partner_id":null,"photo":null}},"name":"Volodymyr","project_id":2,"project_title":"Test project 1","sends":0,"sent_mails":[],"subchannel_id":null,"unsub_promo":[],"unsubscribe":[],"unsubscribe_types":[{"created_at":"2021-07-05 15:06:24","sharded":0,"type_id":1,"type_sig":"Undefined"},{"created_at":"2021-07-05 15:06:24","sharded":0,"type_id":2,"type_sig":"Undefined"},{"created_at":"2021-07-05 15:06:24","sharded":0,"type_id":3,"type_sig":"Undefined"},{"created_at":"2021-07-05 15:06:24","sharded":0,"type_id":4,"type_sig":"Undefined"},{"created_at":"2021-07-05 15:06:24","sharded":0,"type_id":5,"type_sig":"Undefined"}],"webpush":{"last_click":null,"last_push":"2020-10-26 14:47:25","reg_date":"2017-02-13 16:55:25"}}}}`),
 			true},
 
 		{"email_user_by_email_and_project_not_found",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{"test@gmail.com", 2},
 			[]byte(`{"_meta":{"count":1,"status":"ERROR","time":3600},"data":{"error":"Not found  user for project 2 and email test@gmail.com"}}`),
 			true},
@@ -524,7 +536,9 @@ func TestSendiosSdk_GetEmailUserByEmailAndProjectId(t *testing.T) {
 			}))
 			defer ts.Close()
 
-			got, err := sdk.Request.Get(ts.URL, fmt.Sprintf("/user/project/%d/email/%s", tt.args.projectId, tt.args.email))
+			var got []byte
+
+			err := sdk.Request.Do(context.Background(), http.MethodGet, ts.URL, fmt.Sprintf("/user/project/%d/email/%s", tt.args.projectId, tt.args.email), nil, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -551,13 +565,13 @@ func TestSendiosSdk_GetEmailUserById(t *testing.T) {
 		wantErr bool
 	}{
 		{"email_user_by_id",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{1},
 			[]byte(`{"_meta":{"count":1,"status":"SUCCESS","time":4507},"data":{"user":{"activation":null,"channel_id":null,"clicks":0,"country":false,"created_at":"2021-06-17 10:29:27","email":"volodymyr.voloshyn@corp.sendios.io","err_response":0,"gender":"m","id":5005,"language":"en","last_mailed":null,"last_online":null,"last_payment":{"active":1,"amount":1,"expires_at":1625479419,"id":1,"payment_count":1,"payment_type":1,"project_id":2,"started_at":1625479419,"user_id":5005},"last_reaction":null,"last_request":null,"meta":{"profile":{"age":null,"ak":null,"partner_id":null,"photo":null}},"name":"Volodymyr","project_id":2,"project_title":"Test project 1","sends":0,"sent_mails":[],"subchannel_id":null,"unsub_promo":[],"unsubscribe":[],"unsubscribe_types":[{"created_at":"2021-07-05 15:06:24","sharded":0,"type_id":1,"type_sig":"Undefined"},{"created_at":"2021-07-05 15:06:24","sharded":0,"type_id":2,"type_sig":"Undefined"},{"created_at":"2021-07-05 15:06:24","sharded":0,"type_id":3,"type_sig":"Undefined"},{"created_at":"2021-07-05 15:06:24","sharded":0,"type_id":4,"type_sig":"Undefined"},{"created_at":"2021-07-05 15:06:24","sharded":0,"type_id":5,"type_sig":"Undefined"}],"webpush":{"last_click":null,"last_push":"2020-10-26 14:47:25","reg_date":"2017-02-13 16:55:25"}}}}`),
 			true},
 
 		{"email_user_by_id_not_found",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{2},
 			[]byte(`{"_meta":{"count":1,"status":"ERROR","time":4477},"data":{"error":"User not found"}}`),
 			true},
@@ -575,7 +589,9 @@ func TestSendiosSdk_GetEmailUserById(t *testing.T) {
 			}))
 			defer ts.Close()
 
-			got, err := sdk.Request.Get(ts.URL, fmt.Sprintf("/user/id/%d", tt.args.id))
+			var got []byte
+
+			err := sdk.Request.Do(context.Background(), http.MethodGet, ts.URL, fmt.Sprintf("/user/id/%d", tt.args.id), nil, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -601,19 +617,19 @@ func TestSendiosSdk_GetPushUserById(t *testing.T) {
 		wantErr bool
 	}{
 		{"push_user_by_user_id",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{1},
 			[]byte(`{"_meta":{"count":1,"status":"SUCCESS","time":3630},"data":{"result":{"hash":"NULL","id":717067,"invalid":null,"last_click":null,"last_online":null,"last_push":"2020-10-26 14:47:25","last_show":null,"meta":{"auth_token":"FpOCjghkdFV1JEUKOgJ-cw==","public_key":"BHv8Z_EomoVe33d2oGdwdbmT9Jd3rJd4VPZRXjzNPgtJzeHOu-hERyap53cV74nKVPQQ7BlNVwAKMGZaZsSr16A=","url":"https://android.googleapis.com/gcm/send/dtnHwCXnsBw:APA91bFPw56tcPNcVdWdOCzqhIdnPf4pyBIaTXg_tjMDDlLHlk4zo5BwpOTYJGbG_ZpMCuBZg_R3LIJaMalUHCQExEWD7__CNpYRcR-UHhkoHa_r9p3sD00kYr9qy9iBCztSTGWgZHzy"},"platform_id":null,"project_id":2,"reg_date":"2017-02-13 16:55:25","send_platform_id":null,"type":null,"user_id":5005}}}`),
 			true},
 
 		{"push_user_by_user_id_not_found",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{0},
 			[]byte(`{"_meta":{"count":1,"status":"ERROR","time":3464},"data":{"error":"Not found user by id: 0"}}`),
 			true},
 
 		{"push_user_by_user_id_forbidden",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{2},
 			[]byte(`{"_meta":{"count":1,"status":"ERROR","time":4494},"data":{"error":"Forbidden"}}`),
 			true},
@@ -631,7 +647,9 @@ func TestSendiosSdk_GetPushUserById(t *testing.T) {
 			}))
 			defer ts.Close()
 
-			got, err := sdk.Request.Get(ts.URL, fmt.Sprintf("/webpush/user/get/%d", tt.args.userId))
+			var got []byte
+
+			err := sdk.Request.Do(context.Background(), http.MethodGet, ts.URL, fmt.Sprintf("/webpush/user/get/%d", tt.args.userId), nil, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -657,13 +675,13 @@ func TestSendiosSdk_GetUnsubListByEmailUserId(t *testing.T) {
 		wantErr bool
 	}{
 		{"unsub_list_by_email_user",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{2},
 			[]byte(`{"_meta":{"count":5,"status":"SUCCESS","time":4030},"data":[{"created_at":"2021-07-05 15:06:24","name":"SystemMail07082","type_id":1},{"created_at":"2021-07-05 15:06:24","name":"Test2","type_id":2},{"created_at":"2021-07-05 15:06:24","name":"Qwerty","type_id":3},{"created_at":"2021-07-05 15:06:24","name":"Foobar","type_id":4},{"created_at":"2021-07-05 15:06:24","name":"TriggerMail04082","type_id":5}]}`),
 			true},
 
 		{"unsub_list_by_email_user_not_found",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{0},
 			[]byte(`{"_meta":{"count":1,"status":"ERROR","time":4240},"data":{"error":"User not found"}}`),
 			true},
@@ -681,7 +699,9 @@ func TestSendiosSdk_GetUnsubListByEmailUserId(t *testing.T) {
 			}))
 			defer ts.Close()
 
-			got, err := sdk.Request.Get(ts.URL, fmt.Sprintf("/unsubtypes/%d", tt.args.userId))
+			var got []byte
+
+			err := sdk.Request.Do(context.Background(), http.MethodGet, ts.URL, fmt.Sprintf("/unsubtypes/%d", tt.args.userId), nil, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -707,12 +727,12 @@ func TestSendiosSdk_GetUnsubscribeReason(t *testing.T) {
 		wantErr bool
 	}{
 		{"unsub_reason_by_user_not_found",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{0},
 			[]byte(`{"_meta":{"count":1,"status":"ERROR","time":3537},"data":{"error":"User not found"}}`),
 			true},
 		{"unsub_reason_by_user",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{2},
 			[]byte(`{"_meta":{"count":1,"status":"SUCCESS","time":3421},"data":{"result":false}}`),
 			true},
@@ -730,7 +750,9 @@ func TestSendiosSdk_GetUnsubscribeReason(t *testing.T) {
 			}))
 			defer ts.Close()
 
-			got, err := sdk.Request.Get(ts.URL, fmt.Sprintf("/unsub/unsubreason/%d", tt.args.UserId))
+			var got []byte
+
+			err := sdk.Request.Do(context.Background(), http.MethodGet, ts.URL, fmt.Sprintf("/unsub/unsubreason/%d", tt.args.UserId), nil, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -756,7 +778,7 @@ func TestSendiosSdk_GetUnsubscribesByDate(t *testing.T) {
 		wantErr bool
 	}{
 		{"unsub_by_date",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{time.Now().Unix()},
 			[]byte(`{"_meta":{"count":1,"status":"ERROR","time":4477},"data":{"error":"User not found"}}`),
 			true},
@@ -774,7 +796,9 @@ func TestSendiosSdk_GetUnsubscribesByDate(t *testing.T) {
 			}))
 			defer ts.Close()
 
-			got, err := sdk.Request.Get(ts.URL, fmt.Sprintf("/unsub/list/%d", tt.args.time))
+			var got []byte
+
+			err := sdk.Request.Do(context.Background(), http.MethodGet, ts.URL, fmt.Sprintf("/unsub/list/%d", tt.args.time), nil, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -801,13 +825,13 @@ func TestSendiosSdk_GetUserFieldsByEmailAndProjectId(t *testing.T) {
 		wantErr bool
 	}{
 		{"user_fields_by_email",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{"volodymyr.voloshyn@corp.sendios.io", 2},
 			[]byte(`{"_meta":{"count":1,"status":"SUCCESS","time":5623},"data":{"result":{"custom_fields":[],"user":{"city_id":null,"confirm":1,"country_id":null,"created_at":"2021-06-17 10:29:27","email":"volodymyr.voloshyn@corp.sendios.io","err_response":0,"gender":"m","id":5005,"language":"en","last_mailed":0,"last_online":0,"last_reaction":0,"list_id":0,"meta":"[]","name":"Volodymyr","platform_id":1,"project_id":2,"status":1,"valid_id":null,"vendor_id":3,"vip":1}}}}`),
 			true},
 
 		{"user_fields_by_email_not_found",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{"example@gmail.com", 2},
 			[]byte(`{"_meta":{"count":1,"status":"ERROR","time":3726},"data":{"error":"User not found."}}`),
 			true},
@@ -825,7 +849,9 @@ func TestSendiosSdk_GetUserFieldsByEmailAndProjectId(t *testing.T) {
 			}))
 			defer ts.Close()
 
-			got, err := sdk.Request.Get(ts.URL, fmt.Sprintf("/userfields/project/%d/email/%s", tt.args.projectId, tt.args.email))
+			var got []byte
+
+			err := sdk.Request.Do(context.Background(), http.MethodGet, ts.URL, fmt.Sprintf("/userfields/project/%d/email/%s", tt.args.projectId, tt.args.email), nil, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -884,12 +910,12 @@ func TestSendiosSdk_IsUnsubByEmailAndProjectId(t *testing.T) {
 		wantErr bool
 	}{
 		{"is_unsub_by_email_not_found",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{0},
 			[]byte(`{"_meta":{"count":1,"status":"ERROR","time":3758},"data":{"error":"User not found"}}`),
 			true},
 		{"is_unsub_by_email",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{2},
 			[]byte(`{"_meta":{"count":1,"status":"SUCCESS","time":3387},"data":{"result":false}}`),
 			true},
@@ -907,7 +933,9 @@ func TestSendiosSdk_IsUnsubByEmailAndProjectId(t *testing.T) {
 			}))
 			defer ts.Close()
 
-			got, err := sdk.Request.Get(ts.URL, fmt.Sprintf("/unsub/isunsub/%d", tt.args.userId))
+			var got []byte
+
+			err := sdk.Request.Do(context.Background(), http.MethodGet, ts.URL, fmt.Sprintf("/unsub/isunsub/%d", tt.args.userId), nil, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -933,12 +961,12 @@ func TestSendiosSdk_IsUnsubUser(t *testing.T) {
 		wantErr bool
 	}{
 		{"is_unsub_not_found",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{0},
 			[]byte(`{"_meta":{"count":1,"status":"ERROR","time":3758},"data":{"error":"User not found"}}`),
 			true},
 		{"is_unsub",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{2},
 			[]byte(`{"_meta":{"count":1,"status":"SUCCESS","time":3387},"data":{"result":false}}`),
 			true},
@@ -956,7 +984,9 @@ func TestSendiosSdk_IsUnsubUser(t *testing.T) {
 			}))
 			defer ts.Close()
 
-			got, err := sdk.Request.Get(ts.URL, fmt.Sprintf("/unsub/isunsub/%d", tt.args.userId))
+			var got []byte
+
+			err := sdk.Request.Do(context.Background(), http.MethodGet, ts.URL, fmt.Sprintf("/unsub/isunsub/%d", tt.args.userId), nil, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -982,7 +1012,7 @@ func TestSendiosSdk_RemoveAllUnsubTypesByEmailUser(t *testing.T) {
 		wantErr bool
 	}{
 		{"remove_all_unsub_types",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{0},
 			[]byte(`{"_meta":{"count":1,"status":"SUCCESS","time":4969},"data":null}`),
 			true},
@@ -1000,7 +1030,9 @@ func TestSendiosSdk_RemoveAllUnsubTypesByEmailUser(t *testing.T) {
 			}))
 			defer ts.Close()
 
-			got, err := sdk.Request.Delete(ts.URL, fmt.Sprintf("/unsubtypes/all/%d", tt.args.userId), nil)
+			var got []byte
+
+			err := sdk.Request.Do(context.Background(), http.MethodDelete, ts.URL, fmt.Sprintf("/unsubtypes/all/%d", tt.args.userId), nil, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -1027,19 +1059,19 @@ func TestSendiosSdk_RemoveUnsubTypesByEmailUser(t *testing.T) {
 		wantErr bool
 	}{
 		{"remove_unsub_types_email_user",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{1, []int{1, 2, 3}},
 			[]byte(`{"_meta":{"count":1,"status":"SUCCESS","time":4275},"data":null}`),
 			true},
 
 		{"remove_unsub_types_email_user_not_found",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{0, []int{1, 2, 3}},
 			[]byte(`{"_meta":{"count":1,"status":"ERROR","time":3615},"data":{"error":"User not found"}}`),
 			true},
 
 		{"remove_unsub_types_email_user_empty_types",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{1, []int{}},
 			[]byte(`{"_meta":{"count":1,"status":"SUCCESS","time":3460},"data":null}`),
 			true},
@@ -1059,7 +1091,9 @@ func TestSendiosSdk_RemoveUnsubTypesByEmailUser(t *testing.T) {
 
 			params := internal.TypeIds{TypeIds: tt.args.typeIds}
 
-			got, err := sdk.Request.Delete(ts.URL, fmt.Sprintf("/unsubtypes/nodiff/%d", tt.args.userId), params)
+			var got []byte
+
+			err := sdk.Request.Do(context.Background(), http.MethodDelete, ts.URL, fmt.Sprintf("/unsubtypes/nodiff/%d", tt.args.userId), params, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -1093,7 +1127,7 @@ func TestSendiosSdk_SendEmail(t *testing.T) {
 		wantErr bool
 	}{
 		{"send_email",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{3, 1, 1, 2, "test@gmail.com", map[string]string{"id": "1"}, map[string]string{"data": "test"}, map[string]string{"meta": "email"}},
 			[]byte(`{"_meta":{"count":1,"status":"SUCCESS","time":4275},"data":null}`),
 			true},
@@ -1111,7 +1145,9 @@ func TestSendiosSdk_SendEmail(t *testing.T) {
 			}))
 			defer ts.Close()
 
-			got, err := sdk.Request.Get(ts.URL, "/push/system")
+			var got []byte
+
+			err := sdk.Request.Do(context.Background(), http.MethodGet, ts.URL, "/push/system", nil, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -1204,7 +1240,7 @@ func TestSendiosSdk_SendPushByProject(t *testing.T) {
 	}
 }
 
-//later
+// later
 func TestSendiosSdk_SendPushByProjectIdAndHash(t *testing.T) {
 	type fields struct {
 		Request *internal.Request
@@ -1262,7 +1298,7 @@ func TestSendiosSdk_SetOnlineByEmailAndProjectId(t *testing.T) {
 		wantErr bool
 	}{
 		{"set_online_by_email_and_project",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{"volodymyr.voloshyn@corp.sendio.io", 2},
 			[]byte(`{"status":"Accepted"}`),
 			true},
@@ -1286,7 +1322,8 @@ func TestSendiosSdk_SetOnlineByEmailAndProjectId(t *testing.T) {
 				EncodedEmail: encodedEmail,
 				Timestamp:    time.Now(),
 			}
-			got, err := sdk.Request.Put(ts.URL, fmt.Sprintf("/users/project/%d/email/%s/online", tt.args.projectId, encodedEmail), params)
+			var got []byte
+			err := sdk.Request.Do(context.Background(), http.MethodPut, ts.URL, fmt.Sprintf("/users/project/%d/email/%s/online", tt.args.projectId, encodedEmail), params, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -1312,7 +1349,7 @@ func TestSendiosSdk_SetOnlineByUser(t *testing.T) {
 		wantErr bool
 	}{
 		{"set_online_by_user",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{1},
 			[]byte(`{"status":"Accepted"}`),
 			true},
@@ -1331,7 +1368,9 @@ func TestSendiosSdk_SetOnlineByUser(t *testing.T) {
 
 			params := internal.OnlineByUser{UserId: tt.args.userId, Timestamp: time.Now()}
 
-			got, err := sdk.Request.Put(ts.URL, fmt.Sprintf("/users/%d/online", tt.args.userId), params)
+			var got []byte
+
+			err := sdk.Request.Do(context.Background(), http.MethodPut, ts.URL, fmt.Sprintf("/users/%d/online", tt.args.userId), params, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -1359,7 +1398,7 @@ func TestSendiosSdk_SetUserFieldsByEmailAndProjectId(t *testing.T) {
 		wantErr bool
 	}{
 		{"set_user_fields_by_email",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{"volodymyr.voloshyn@corp.sendio.io", 2, map[string]string{"test": "test"}},
 			[]byte(`{"_meta":{"count":1,"status":"SUCCESS","time":3850},"data":{"result":true}}`),
 			true},
@@ -1378,7 +1417,8 @@ func TestSendiosSdk_SetUserFieldsByEmailAndProjectId(t *testing.T) {
 			defer ts.Close()
 
 			encodedEmail := internal.Base64Encoder(tt.args.email)
-			got, err := sdk.Request.Put(ts.URL, fmt.Sprintf("/userfields/project/%d/emailhash/%s", tt.args.projectId, encodedEmail), tt.args.data)
+			var got []byte
+			err := sdk.Request.Do(context.Background(), http.MethodPut, ts.URL, fmt.Sprintf("/userfields/project/%d/emailhash/%s", tt.args.projectId, encodedEmail), tt.args.data, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -1405,7 +1445,7 @@ func TestSendiosSdk_SetUserFieldsByUserId(t *testing.T) {
 		wantErr bool
 	}{
 		{"set_user_fields_by_user",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{2, map[string]string{"test": "test"}},
 			[]byte(`{"_meta":{"count":1,"status":"SUCCESS","time":4112},"data":{"result":true}}`),
 			true},
@@ -1423,7 +1463,9 @@ func TestSendiosSdk_SetUserFieldsByUserId(t *testing.T) {
 			}))
 			defer ts.Close()
 
-			got, err := sdk.Request.Put(ts.URL, fmt.Sprintf("/userfields/user/%d", tt.args.userId), tt.args.data)
+			var got []byte
+
+			err := sdk.Request.Do(context.Background(), http.MethodPut, ts.URL, fmt.Sprintf("/userfields/user/%d", tt.args.userId), tt.args.data, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -1450,12 +1492,12 @@ func TestSendiosSdk_SubscribeEmailUser(t *testing.T) {
 		wantErr bool
 	}{
 		{"subscribe_email_user",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{2},
 			[]byte(`{"_meta":{"count":1,"status":"SUCCESS","time":3716},"data":{"subscribe":{"rowCount":1}}}`),
 			true},
 		{"subscribe_email_user",
-			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{"3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
+			fields{&internal.Request{Client: &http.Client{Timeout: time.Second * 10}, Auth: &internal.Auth{ClientId: "3", AuthKey: "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6"}}},
 			args{2},
 			[]byte(`{"_meta":{"count":1,"status":"SUCCESS","time":3616},"data":{"subscribe":{"rowCount":0}}}`),
 			true},
@@ -1473,7 +1515,9 @@ func TestSendiosSdk_SubscribeEmailUser(t *testing.T) {
 			}))
 			defer ts.Close()
 
-			got, err := sdk.Request.Delete(ts.URL, fmt.Sprintf("/unsub/%d", tt.args.userId), nil)
+			var got []byte
+
+			err := sdk.Request.Do(context.Background(), http.MethodDelete, ts.URL, fmt.Sprintf("/unsub/%d", tt.args.userId), nil, &got)
 			if err != nil {
 				t.Errorf("expected error to be nil got %v", err)
 			}
@@ -1851,3 +1895,36 @@ func TestSendiosSdk_ValidateEmail(t *testing.T) {
 		})
 	}
 }
+
+func TestSendiosSdk_ChainedLookupsHonourCancellation(t *testing.T) {
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		call func() ([]byte, error)
+	}{
+		{"is_unsub_by_email_and_project_id", func() ([]byte, error) {
+			return sdk.IsUnsubByEmailAndProjectIdCtx(ctx, "test@gmail.com", 1)
+		}},
+		{"get_unsubscribe_reason", func() ([]byte, error) {
+			return sdk.GetUnsubscribeReasonCtx(ctx, "test@gmail.com", 1)
+		}},
+		{"send_push_by_email_user_id", func() ([]byte, error) {
+			return sdk.SendPushByEmailUserIdCtx(ctx, 1, "title", "text", "url", "icon", 1, nil, "image")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.call()
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("expected context canceled error, got %v", err)
+			}
+			if got != nil {
+				t.Errorf("expected nil response, got %s", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	sendios "github.com/sendios/go-sdk"
+	"github.com/sendios/go-sdk/sendiostest"
+)
+
+func TestSendiostest_Users(t *testing.T) {
+	server := sendiostest.NewServer()
+	defer server.Close()
+
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", server.Options()...)
+	ctx := context.Background()
+	user := server.AddUser(sendiostest.User{Email: "test@gmail.com", ProjectId: 2, Name: "Volodymyr"})
+
+	got, err := sdk.EmailUserByEmailAndProjectId(ctx, "test@gmail.com", 2)
+	if err != nil || got.Id != user.Id || got.Name != "Volodymyr" {
+		t.Errorf("EmailUserByEmailAndProjectId() = %+v, %v", got, err)
+	}
+
+	if _, err := sdk.EmailUserById(ctx, 999); !sendios.IsNotFound(err) {
+		t.Errorf("EmailUserById() for unknown user error = %v", err)
+	}
+
+	if _, err := sdk.SetUserFieldsByEmailAndProjectId("test@gmail.com", 2, map[string]string{"plan": "gold"}); err != nil {
+		t.Fatalf("SetUserFieldsByEmailAndProjectId() error = %v", err)
+	}
+	if _, err := sdk.SetUserFieldsByUserId(user.Id, map[string]string{"city": "Kyiv"}); err != nil {
+		t.Fatalf("SetUserFieldsByUserId() error = %v", err)
+	}
+	fields, err := sdk.UserFieldsByUserId(ctx, user.Id)
+	if err != nil || !reflect.DeepEqual(fields.CustomFields, map[string]interface{}{"plan": "gold", "city": "Kyiv"}) {
+		t.Errorf("UserFieldsByUserId() = %+v, %v", fields, err)
+	}
+
+	if _, err := sdk.SetOnlineByUser(user.Id); err != nil {
+		t.Errorf("SetOnlineByUser() error = %v", err)
+	}
+	if _, err := sdk.ForceConfirmByEmailAndProject("test@gmail.com", 2); err != nil {
+		t.Errorf("ForceConfirmByEmailAndProject() error = %v", err)
+	}
+	if _, err := sdk.AddPaymentByEmailAndProjectId("test@gmail.com", 2, 1625479419, 1625479419, 1, 1, 100); err != nil {
+		t.Errorf("AddPaymentByEmailAndProjectId() error = %v", err)
+	}
+
+	stored, _ := server.User(user.Id)
+	if stored.LastOnline.IsZero() || stored.ConfirmedAt.IsZero() {
+		t.Errorf("online and confirm were not recorded: %+v", stored)
+	}
+	if payments := server.Payments(); len(payments) != 1 || payments[0].UserId != user.Id || payments[0].Amount != 100 {
+		t.Errorf("payments = %+v", payments)
+	}
+}
+
+func TestSendiostest_Unsubscribes(t *testing.T) {
+	server := sendiostest.NewServer()
+	defer server.Close()
+
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", server.Options()...)
+	ctx := context.Background()
+	user := server.AddUser(sendiostest.User{Email: "test@gmail.com", ProjectId: 2})
+	other := server.AddUser(sendiostest.User{Email: "other@gmail.com", ProjectId: 2})
+
+	if _, err := sdk.UnsubEmailUserClient(user.Id); err != nil {
+		t.Fatalf("UnsubEmailUserClient() error = %v", err)
+	}
+	status, err := sdk.UnsubStatusByEmailAndProjectId(ctx, "test@gmail.com", 2)
+	if err != nil || !status.Unsubscribed {
+		t.Errorf("UnsubStatusByEmailAndProjectId() = %+v, %v", status, err)
+	}
+
+	if _, err := sdk.UnsubEmailUserByAdmin("other@gmail.com", 2); err != nil {
+		t.Fatalf("UnsubEmailUserByAdmin() error = %v", err)
+	}
+	if !server.IsUnsubscribed(other.Id) {
+		t.Errorf("admin unsubscribe was not recorded")
+	}
+
+	if _, err := sdk.SubscribeEmailUser(user.Id); err != nil {
+		t.Fatalf("SubscribeEmailUser() error = %v", err)
+	}
+	if server.IsUnsubscribed(user.Id) {
+		t.Errorf("user is still unsubscribed")
+	}
+
+	if _, err := sdk.UnsubEmailUserByTypes(user.Id, []int{1, 2, 3}); err != nil {
+		t.Fatalf("UnsubEmailUserByTypes() error = %v", err)
+	}
+	if _, err := sdk.AddTypesToUnsubByEmailUser(user.Id, []int{5}); err != nil {
+		t.Fatalf("AddTypesToUnsubByEmailUser() error = %v", err)
+	}
+	if _, err := sdk.RemoveUnsubTypesByEmailUser(user.Id, []int{2}); err != nil {
+		t.Fatalf("RemoveUnsubTypesByEmailUser() error = %v", err)
+	}
+	types, err := sdk.UnsubTypesByEmailUserId(ctx, user.Id)
+	if err != nil || len(types) != 3 || types[0].TypeId != 1 || types[1].TypeId != 3 || types[2].TypeId != 5 {
+		t.Errorf("UnsubTypesByEmailUserId() = %+v, %v", types, err)
+	}
+
+	if _, err := sdk.RemoveAllUnsubTypesByEmailUser(user.Id); err != nil {
+		t.Fatalf("RemoveAllUnsubTypesByEmailUser() error = %v", err)
+	}
+	if stored, _ := server.User(user.Id); len(stored.UnsubscribedTypes) != 0 {
+		t.Errorf("unsubscribed types = %v", stored.UnsubscribedTypes)
+	}
+}
+
+func TestSendiostest_Sends(t *testing.T) {
+	server := sendiostest.NewServer()
+	defer server.Close()
+
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", server.Options()...)
+	user := server.AddUser(sendiostest.User{Email: "test@gmail.com", ProjectId: 2})
+	push := server.AddPushUser(sendiostest.PushUser{UserId: user.Id, ProjectId: 2, Hash: "abc"})
+
+	if _, err := sdk.SendEmail(3, 7, sendios.Trigger, 2, "test@gmail.com", map[string]string{}, map[string]string{"data": "test"}, nil); err != nil {
+		t.Fatalf("SendEmail() error = %v", err)
+	}
+	emails := server.SentEmails()
+	if len(emails) != 1 || emails[0].Route != "push/trigger" || emails[0].TypeId != 7 || emails[0].User["email"] != "test@gmail.com" {
+		t.Errorf("sent emails = %+v", emails)
+	}
+
+	if _, err := sdk.SendPushByEmailUserId(user.Id, "title", "text", "url", "icon", 1, nil, "image"); err != nil {
+		t.Fatalf("SendPushByEmailUserId() error = %v", err)
+	}
+	if _, err := sdk.SendPushByProjectIdAndHash(2, "abc", "hash title", "text", "url", "icon", 1, nil, "image"); err != nil {
+		t.Fatalf("SendPushByProjectIdAndHash() error = %v", err)
+	}
+	pushes := server.SentPushes()
+	if len(pushes) != 2 || pushes[0].PushUserId != push.Id || pushes[1].Title != "hash title" {
+		t.Errorf("sent pushes = %+v", pushes)
+	}
+
+	if _, err := sdk.UnsubscribePushUserByEmailUserId(user.Id); err != nil {
+		t.Fatalf("UnsubscribePushUserByEmailUserId() error = %v", err)
+	}
+	if stored, _ := server.PushUser(push.Id); !stored.Unsubscribed {
+		t.Errorf("push user is still subscribed")
+	}
+
+	if _, err := sdk.CreatePushUser(user.Id, 2, "https://push", "key", "token"); err != nil {
+		t.Fatalf("CreatePushUser() error = %v", err)
+	}
+}
+
+func TestSendiostest_UnsubscribesByDateAndFailures(t *testing.T) {
+	server := sendiostest.NewServer()
+	defer server.Close()
+
+	now := time.Date(2021, 7, 5, 13, 0, 0, 0, time.UTC)
+	server.SetClock(func() time.Time { return now })
+
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", append(server.Options(), sendios.WithRetryPolicy(sendios.RetryPolicy{MaxAttempts: 1}))...)
+	user := server.AddUser(sendiostest.User{Email: "test@gmail.com", ProjectId: 2})
+
+	if _, err := sdk.UnsubEmailUserBySettings(user.Id); err != nil {
+		t.Fatalf("UnsubEmailUserBySettings() error = %v", err)
+	}
+
+	var list []map[string]interface{}
+	res, err := sdk.GetUnsubscribesByDate(now.Unix())
+	if err != nil {
+		t.Fatalf("GetUnsubscribesByDate() error = %v", err)
+	}
+	if _, err := sendios.DecodeResponse(res, &list); err != nil || len(list) != 1 || list[0]["source_id"] != float64(sendios.SourceSettings) {
+		t.Errorf("unsubscribes = %+v, %v", list, err)
+	}
+
+	server.FailNext("GET unsub/isunsub/{user}", 1, http.StatusInternalServerError)
+	if _, err := sdk.IsUnsubUser(user.Id); !sendios.IsServerError(err) {
+		t.Errorf("expected injected server error, got %v", err)
+	}
+	if _, err := sdk.IsUnsubUser(user.Id); err != nil {
+		t.Errorf("expected injected failure to be consumed, got %v", err)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 4 || requests[0].Route != "unsub/1/source/9" || requests[0].Method != http.MethodPost {
+		t.Errorf("requests = %+v", requests)
+	}
+}
@@ -0,0 +1,248 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	sendios "github.com/sendios/go-sdk"
+	"github.com/sendios/go-sdk/sendiostest"
+)
+
+func TestSuppressionList_MergesAndSnapshots(t *testing.T) {
+	dir, err := ioutil.TempDir("", "suppression")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "suppressions.json")
+
+	list, err := sendios.NewSuppressionList(path)
+	if err != nil {
+		t.Fatalf("NewSuppressionList() error = %v", err)
+	}
+
+	steps := []func() error{
+		func() error { return list.Add(sendios.Suppression{UserId: 1, All: true}) },
+		func() error {
+			return list.Add(sendios.Suppression{ProjectId: 2, Email: "Known@Gmail.com", TypeIds: []int{7}})
+		},
+		// links the user id to the entry known by email, merging both
+		func() error {
+			return list.Add(sendios.Suppression{UserId: 3, ProjectId: 2, Email: "known@gmail.com", TypeIds: []int{5, 7}})
+		},
+		func() error { return list.SetTypes(4, []int{9, 8}) },
+		func() error { return list.RemoveTypes(4, []int{9}) },
+		func() error { return list.Add(sendios.Suppression{UserId: 5, TypeIds: []int{1}}) },
+		// an empty list resubscribes to nothing
+		func() error { return list.RemoveTypes(5, nil) },
+		func() error { return list.RemoveAllTypes(5) },
+		// only links, nothing is known about the user
+		func() error { return list.Add(sendios.Suppression{UserId: 6, ProjectId: 2, Email: "other@gmail.com"}) },
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("step %d error = %v", i, err)
+		}
+	}
+
+	want := []sendios.Suppression{
+		{UserId: 1, All: true},
+		{UserId: 3, ProjectId: 2, Email: "known@gmail.com", TypeIds: []int{5, 7}},
+		{UserId: 4, TypeIds: []int{8}},
+	}
+	if got := list.Suppressions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Suppressions() = %+v, want %+v", got, want)
+	}
+
+	reopened, err := sendios.NewSuppressionList(path)
+	if err != nil {
+		t.Fatalf("NewSuppressionList() error = %v", err)
+	}
+	if got := reopened.Suppressions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("reopened Suppressions() = %+v, want %+v", got, want)
+	}
+	if s, ok := reopened.Get(2, "KNOWN@gmail.com "); !ok || s.UserId != 3 || !s.Suppresses(5) || s.Suppresses(6) {
+		t.Errorf("Get() = %+v, %v", s, ok)
+	}
+
+	if err := reopened.Resubscribe(1); err != nil {
+		t.Fatalf("Resubscribe() error = %v", err)
+	}
+	if _, ok := reopened.GetUser(1); ok || reopened.Len() != 2 {
+		t.Errorf("user 1 still suppressed, %d entries", reopened.Len())
+	}
+}
+
+func TestSendEmail_SuppressionCheck(t *testing.T) {
+	server := sendiostest.NewServer()
+	defer server.Close()
+
+	list, err := sendios.NewSuppressionList("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := append(server.Options(), sendios.WithRetryPolicy(sendios.RetryPolicy{MaxAttempts: 1}), sendios.WithSuppressionList(list))
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", append(opts, sendios.WithSuppressionCheck())...)
+	unchecked := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", opts...)
+
+	byTypes := server.AddUser(sendiostest.User{Email: "types@gmail.com", ProjectId: 2})
+	bySettings := server.AddUser(sendiostest.User{Email: "settings@gmail.com", ProjectId: 2})
+	server.AddUser(sendiostest.User{Email: "admin@gmail.com", ProjectId: 2})
+	resolvedBySettings := server.AddUser(sendiostest.User{Email: "resolved.settings@gmail.com", ProjectId: 2})
+	resolvedByClient := server.AddUser(sendiostest.User{Email: "resolved.client@gmail.com", ProjectId: 2})
+
+	// users only known by id are matched through the user id cache: resolved before the
+	// unsubscribe, the user is linked to the email, resolved after, the send looks the id up
+	if _, err := sdk.IsUnsubByEmailAndProjectId("types@gmail.com", 2); err != nil {
+		t.Fatalf("IsUnsubByEmailAndProjectId() error = %v", err)
+	}
+	if _, err := sdk.UnsubEmailUserByTypes(byTypes.Id, []int{5}); err != nil {
+		t.Fatalf("UnsubEmailUserByTypes() error = %v", err)
+	}
+	if _, err := sdk.UnsubEmailUserBySettings(bySettings.Id); err != nil {
+		t.Fatalf("UnsubEmailUserBySettings() error = %v", err)
+	}
+	if _, err := sdk.IsUnsubByEmailAndProjectId("settings@gmail.com", 2); err != nil {
+		t.Fatalf("IsUnsubByEmailAndProjectId() error = %v", err)
+	}
+	// resolved first, the unsubscribe invalidates the cache but still links the email
+	for _, email := range []string{"resolved.settings@gmail.com", "resolved.client@gmail.com"} {
+		if _, err := sdk.IsUnsubByEmailAndProjectId(email, 2); err != nil {
+			t.Fatalf("IsUnsubByEmailAndProjectId(%s) error = %v", email, err)
+		}
+	}
+	if _, err := sdk.UnsubEmailUserBySettings(resolvedBySettings.Id); err != nil {
+		t.Fatalf("UnsubEmailUserBySettings() error = %v", err)
+	}
+	if _, err := sdk.UnsubEmailUserClient(resolvedByClient.Id); err != nil {
+		t.Fatalf("UnsubEmailUserClient() error = %v", err)
+	}
+	for _, user := range []sendiostest.User{byTypes, resolvedBySettings, resolvedByClient} {
+		if s, ok := list.GetUser(user.Id); !ok || s.Email != user.Email {
+			t.Errorf("GetUser(%d) = %+v, %v, want the user linked to the cached email", user.Id, s, ok)
+		}
+	}
+	for _, request := range server.Requests() {
+		if strings.HasPrefix(request.Route, "user/id/") {
+			t.Errorf("unsubscribing looked the user up: %s %s", request.Method, request.Route)
+		}
+	}
+	if _, err := sdk.UnsubEmailUserByAdmin("admin@gmail.com", 2); err != nil {
+		t.Fatalf("UnsubEmailUserByAdmin() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		sdk        *sendios.SendiosSdk
+		email      string
+		typeId     int
+		suppressed bool
+	}{
+		{"unsubscribed type", sdk, "types@gmail.com", 5, true},
+		{"other type", sdk, "types@gmail.com", 6, false},
+		{"unsubscribed by settings", sdk, "settings@gmail.com", 6, true},
+		{"unsubscribed by admin", sdk, "admin@gmail.com", 1, true},
+		{"resolved then unsubscribed by settings", sdk, "resolved.settings@gmail.com", 6, true},
+		{"resolved then unsubscribed by client", sdk, "resolved.client@gmail.com", 6, true},
+		{"unknown user", sdk, "new@gmail.com", 5, false},
+		{"check disabled", unchecked, "settings@gmail.com", 6, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent := len(server.SentEmails())
+			_, err := tt.sdk.SendEmail(1, tt.typeId, sendios.Trigger, 2, tt.email, nil, nil, nil)
+			if errors.Is(err, sendios.ErrSuppressed) != tt.suppressed {
+				t.Fatalf("SendEmail() error = %v, suppressed = %v", err, tt.suppressed)
+			}
+			if !tt.suppressed && err != nil {
+				t.Fatalf("SendEmail() error = %v", err)
+			}
+			want := 1
+			if tt.suppressed {
+				want = 0
+			}
+			if got := len(server.SentEmails()) - sent; got != want {
+				t.Errorf("sent %d emails, want %d", got, want)
+			}
+		})
+	}
+
+	if _, err := sdk.SubscribeEmailUser(bySettings.Id); err != nil {
+		t.Fatalf("SubscribeEmailUser() error = %v", err)
+	}
+	if _, err := sdk.RemoveAllUnsubTypesByEmailUser(byTypes.Id); err != nil {
+		t.Fatalf("RemoveAllUnsubTypesByEmailUser() error = %v", err)
+	}
+	for _, email := range []string{"settings@gmail.com", "types@gmail.com"} {
+		if _, err := sdk.SendEmail(1, 5, sendios.Trigger, 2, email, nil, nil, nil); err != nil {
+			t.Errorf("SendEmail(%s) after resubscribing error = %v", email, err)
+		}
+	}
+}
+
+func TestUnsubFeed_FeedsSuppressionList(t *testing.T) {
+	server, other, now := newUnsubFeedTestSdk()
+	defer server.Close()
+
+	list, err := sendios.NewSuppressionList("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", append(server.Options(), sendios.WithSuppressionList(list), sendios.WithSuppressionCheck())...)
+
+	user := unsubscribe(t, server, other, "first@gmail.com")
+
+	feed, err := sdk.NewUnsubFeed(sendios.UnsubFeedOptions{Since: *now})
+	if err != nil {
+		t.Fatalf("NewUnsubFeed() error = %v", err)
+	}
+	if _, err := feed.Poll(context.Background()); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+
+	if s, ok := list.Get(2, "first@gmail.com"); !ok || s.UserId != user.Id || !s.All {
+		t.Errorf("Get() = %+v, %v", s, ok)
+	}
+	if _, err := sdk.SendEmail(1, 5, sendios.Trigger, 2, "first@gmail.com", nil, nil, nil); !errors.Is(err, sendios.ErrSuppressed) {
+		t.Errorf("SendEmail() error = %v, want ErrSuppressed", err)
+	}
+}
+
+func TestSuppressionList_SaveFailureDoesNotFailCall(t *testing.T) {
+	server := sendiostest.NewServer()
+	defer server.Close()
+	user := server.AddUser(sendiostest.User{Email: "test@gmail.com", ProjectId: 2})
+
+	dir, err := ioutil.TempDir("", "suppression")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the directory of the snapshot does not exist, so every save fails
+	list, err := sendios.NewSuppressionList(filepath.Join(dir, "missing", "suppressions.json"))
+	if err != nil {
+		t.Fatalf("NewSuppressionList() error = %v", err)
+	}
+	logger := &recordingLogger{}
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", append(server.Options(), sendios.WithSuppressionList(list), sendios.WithLogger(logger))...)
+
+	if _, err := sdk.UnsubEmailUserBySettings(user.Id); err != nil {
+		t.Fatalf("UnsubEmailUserBySettings() error = %v, want the api result", err)
+	}
+	if !server.IsUnsubscribed(user.Id) {
+		t.Error("user is not unsubscribed in the api")
+	}
+	if s, ok := list.GetUser(user.Id); !ok || !s.All {
+		t.Errorf("GetUser() = %+v, %v, want the change kept in memory", s, ok)
+	}
+	if !strings.Contains(logger.String(), "suppression list update failed") {
+		t.Errorf("save failure was not logged: %s", logger)
+	}
+}
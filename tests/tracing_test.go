@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	sendios "github.com/sendios/go-sdk"
+	"github.com/sendios/go-sdk/sendiostest"
+)
+
+func TestWithTracer(t *testing.T) {
+	server := sendiostest.NewServer()
+	defer server.Close()
+	server.AddUser(sendiostest.User{Email: "test@gmail.com", ProjectId: 2})
+
+	type span struct {
+		attrs      sendios.SpanAttributes
+		statusCode int
+		err        error
+	}
+	var started []sendios.SpanAttributes
+	var ended []span
+	tracer := sendios.TraceHooks{
+		OnStart: func(ctx context.Context, attrs sendios.SpanAttributes) context.Context {
+			started = append(started, attrs)
+
+			return sendios.ContextWithTraceparent(ctx, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		},
+		OnEnd: func(ctx context.Context, attrs sendios.SpanAttributes, statusCode int, err error) {
+			ended = append(ended, span{attrs, statusCode, err})
+		},
+	}
+	options := append(server.Options(), sendios.WithTracer(tracer))
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", options...)
+
+	if _, err := sdk.UnsubEmailUserByAdmin("test@gmail.com", 2); err != nil {
+		t.Fatalf("UnsubEmailUserByAdmin() error = %v", err)
+	}
+	if _, err := sdk.GetEmailUserById(999); err == nil {
+		t.Fatal("GetEmailUserById() for unknown user error = nil")
+	}
+
+	want := []span{
+		{sendios.SpanAttributes{Operation: "UnsubEmailUserByAdmin", Method: http.MethodPost, RouteTemplate: "unsub/admin/%d/email/%s", ProjectId: 2}, http.StatusOK, nil},
+		{sendios.SpanAttributes{Operation: "GetEmailUserById", Method: http.MethodGet, RouteTemplate: "user/id/%d"}, http.StatusNotFound, nil},
+	}
+	if len(started) != 2 || len(ended) != 2 {
+		t.Fatalf("started = %+v, ended = %+v", started, ended)
+	}
+	for i, tt := range want {
+		if started[i] != tt.attrs || ended[i].attrs != tt.attrs || ended[i].statusCode != tt.statusCode {
+			t.Errorf("span %d = %+v, %+v, want %+v", i, started[i], ended[i], tt)
+		}
+	}
+	if ended[0].err != nil || !sendios.IsNotFound(ended[1].err) {
+		t.Errorf("span errors = %v, %v", ended[0].err, ended[1].err)
+	}
+
+	for _, request := range server.Requests() {
+		if got := request.Header.Get("traceparent"); got != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+			t.Errorf("traceparent of %s = %q", request.Route, got)
+		}
+	}
+}
+
+func TestContextWithTraceparent(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceparent string
+		want        string
+	}{
+		{"valid", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{"invalid", "not-a-traceparent", ""},
+		{"uppercase", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-01", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := sendiostest.NewServer()
+			defer server.Close()
+
+			sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", server.Options()...)
+			ctx := sendios.ContextWithTraceparent(context.Background(), tt.traceparent)
+			_, _ = sdk.GetEmailUserByIdCtx(ctx, 1)
+
+			requests := server.Requests()
+			if len(requests) != 1 || requests[0].Header.Get("traceparent") != tt.want {
+				t.Errorf("requests = %+v, want traceparent %q", requests, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	sendios "github.com/sendios/go-sdk"
+)
+
+func newTypedTestSdk(body string) (*sendios.SendiosSdk, *httptest.Server) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		fmt.Fprintln(w, body)
+	}))
+
+	return sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", sendios.WithApiV1BaseUrl(ts.URL)), ts
+}
+
+func TestSendiosSdk_TypedMethods(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		body string
+		call func(sdk *sendios.SendiosSdk) (interface{}, error)
+		want interface{}
+	}{
+		{"email_user_by_id",
+			`{"_meta":{"count":1,"status":"SUCCESS","time":4507},"data":{"user":{"activation":null,"clicks":0,"created_at":"2021-06-17 10:29:27","email":"volodymyr.voloshyn@corp.sendios.io","gender":"m","id":5005,"language":"en","name":"Volodymyr","project_id":2,"project_title":"Test project 1","sends":0,"unsubscribe_types":[{"created_at":"2021-07-05 15:06:24","sharded":0,"type_id":1,"type_sig":"Undefined"}]}}}`,
+			func(sdk *sendios.SendiosSdk) (interface{}, error) { return sdk.EmailUserById(ctx, 5005) },
+			&sendios.EmailUser{Id: 5005, Email: "volodymyr.voloshyn@corp.sendios.io", ProjectId: 2, ProjectTitle: "Test project 1", Name: "Volodymyr", Gender: "m", Language: "en", CreatedAt: "2021-06-17 10:29:27",
+				UnsubscribeTypes: []sendios.UserUnsubscribeType{{TypeId: 1, CreatedAt: "2021-07-05 15:06:24"}}}},
+		{"push_user_by_id",
+			`{"_meta":{"count":1,"status":"SUCCESS","time":3630},"data":{"result":{"hash":"NULL","id":717067,"last_push":"2020-10-26 14:47:25","meta":{"auth_token":"token","public_key":"key","url":"https://android.googleapis.com"},"project_id":2,"reg_date":"2017-02-13 16:55:25","user_id":5005}}}`,
+			func(sdk *sendios.SendiosSdk) (interface{}, error) { return sdk.PushUserById(ctx, 5005) },
+			&sendios.PushUser{Id: 717067, UserId: 5005, ProjectId: 2, Hash: "NULL", RegDate: "2017-02-13 16:55:25", LastPush: "2020-10-26 14:47:25",
+				Meta: map[string]string{"auth_token": "token", "public_key": "key", "url": "https://android.googleapis.com"}}},
+		{"unsub_status",
+			`{"_meta":{"count":1,"status":"SUCCESS","time":3387},"data":{"result":true}}`,
+			func(sdk *sendios.SendiosSdk) (interface{}, error) { return sdk.UnsubStatusByUserId(ctx, 5005) },
+			&sendios.UnsubStatus{Unsubscribed: true}},
+		{"unsub_types",
+			`{"_meta":{"count":2,"status":"SUCCESS","time":4030},"data":[{"created_at":"2021-07-05 15:06:24","name":"SystemMail07082","type_id":1},{"created_at":"2021-07-05 15:06:24","name":"Test2","type_id":2}]}`,
+			func(sdk *sendios.SendiosSdk) (interface{}, error) { return sdk.UnsubTypesByEmailUserId(ctx, 5005) },
+			[]sendios.UnsubType{{TypeId: 1, Name: "SystemMail07082", CreatedAt: "2021-07-05 15:06:24"}, {TypeId: 2, Name: "Test2", CreatedAt: "2021-07-05 15:06:24"}}},
+		{"user_fields_with_empty_custom_fields",
+			`{"_meta":{"count":1,"status":"SUCCESS","time":5623},"data":{"result":{"custom_fields":[],"user":{"email":"test@gmail.com","id":5005}}}}`,
+			func(sdk *sendios.SendiosSdk) (interface{}, error) { return sdk.UserFieldsByUserId(ctx, 5005) },
+			&sendios.UserFields{User: map[string]interface{}{"email": "test@gmail.com", "id": float64(5005)}, CustomFields: map[string]interface{}{}}},
+		{"user_fields_with_custom_fields",
+			`{"_meta":{"count":1,"status":"SUCCESS","time":5623},"data":{"result":{"custom_fields":{"plan":"gold"},"user":{"id":5005}}}}`,
+			func(sdk *sendios.SendiosSdk) (interface{}, error) {
+				return sdk.UserFieldsByEmailAndProjectId(ctx, "test@gmail.com", 2)
+			},
+			&sendios.UserFields{User: map[string]interface{}{"id": float64(5005)}, CustomFields: map[string]interface{}{"plan": "gold"}}},
+		{"check_email",
+			`{"_meta":{"count":7,"status":"SUCCESS","time":3499},"data":{"domain":"gmail.com","email":"test@gmail.com","orig":"test@gmail.com","reason":"system","trusted":true,"valid":false,"vendor":"Google"}}`,
+			func(sdk *sendios.SendiosSdk) (interface{}, error) {
+				return sdk.CheckEmailResult(ctx, "test@gmail.com", true)
+			},
+			&sendios.CheckEmailResult{Email: "test@gmail.com", Orig: "test@gmail.com", Domain: "gmail.com", Vendor: "Google", Reason: "system", Trusted: true}},
+		{"buying_decision",
+			`{"_meta":{"count":2,"status":"SUCCESS","time":3923},"data":{"decision":false,"email":"test@gmail.com"}}`,
+			func(sdk *sendios.SendiosSdk) (interface{}, error) { return sdk.BuyingDecision(ctx, "test@gmail.com") },
+			&sendios.BuyingDecision{Email: "test@gmail.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sdk, ts := newTypedTestSdk(tt.body)
+			defer ts.Close()
+
+			got, err := tt.call(sdk)
+			if err != nil {
+				t.Fatalf("expected error to be nil got %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendiosSdk_TypedMethodsMetaError(t *testing.T) {
+	sdk, ts := newTypedTestSdk(`{"_meta":{"count":1,"status":"ERROR","time":4477},"data":{"error":"User not found"}}`)
+	defer ts.Close()
+
+	got, err := sdk.EmailUserById(context.Background(), 0)
+	if got != nil {
+		t.Errorf("expected nil user, got %+v", got)
+	}
+
+	var apiError *sendios.APIError
+	if !errors.As(err, &apiError) {
+		t.Fatalf("expected APIError, got %v", err)
+	}
+	if apiError.MetaStatus != sendios.MetaStatusError || apiError.Message != "User not found" {
+		t.Errorf("unexpected error %+v", apiError)
+	}
+}
+
+func TestDecodeResponse(t *testing.T) {
+	var data map[string]interface{}
+	meta, err := sendios.DecodeResponse([]byte(`{"_meta":{"count":3,"status":"SUCCESS","time":3701},"data":{"message":"done","status":true}}`), &data)
+	if err != nil {
+		t.Fatalf("expected error to be nil got %v", err)
+	}
+
+	if !reflect.DeepEqual(meta, sendios.Meta{Count: 3, Status: "SUCCESS", Time: 3701}) {
+		t.Errorf("meta = %+v", meta)
+	}
+	if !reflect.DeepEqual(data, map[string]interface{}{"message": "done", "status": true}) {
+		t.Errorf("data = %+v", data)
+	}
+}
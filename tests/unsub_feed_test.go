@@ -0,0 +1,258 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	sendios "github.com/sendios/go-sdk"
+	"github.com/sendios/go-sdk/sendiostest"
+)
+
+func newUnsubFeedTestSdk() (*sendiostest.Server, *sendios.SendiosSdk, *time.Time) {
+	server := sendiostest.NewServer()
+	now := time.Date(2021, 7, 5, 13, 0, 0, 0, time.UTC)
+	server.SetClock(func() time.Time { return now })
+
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", append(server.Options(), sendios.WithRetryPolicy(sendios.RetryPolicy{MaxAttempts: 1}))...)
+
+	return server, sdk, &now
+}
+
+func unsubscribe(t *testing.T, server *sendiostest.Server, sdk *sendios.SendiosSdk, email string) sendiostest.User {
+	user := server.AddUser(sendiostest.User{Email: email, ProjectId: 2})
+	if _, err := sdk.UnsubEmailUserBySettings(user.Id); err != nil {
+		t.Fatalf("UnsubEmailUserBySettings() error = %v", err)
+	}
+
+	return user
+}
+
+func TestUnsubFeed_DeliversOnceAcrossPollsAndRestarts(t *testing.T) {
+	server, sdk, now := newUnsubFeedTestSdk()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "unsubfeed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var got []sendios.UnsubEvent
+	opts := sendios.UnsubFeedOptions{
+		Checkpoint: &sendios.FileCheckpointStore{Path: filepath.Join(dir, "checkpoint.json")},
+		Since:      *now,
+		Handler: func(ctx context.Context, event sendios.UnsubEvent) error {
+			got = append(got, event)
+			return nil
+		},
+	}
+	feed, err := sdk.NewUnsubFeed(opts)
+	if err != nil {
+		t.Fatalf("NewUnsubFeed() error = %v", err)
+	}
+	ctx := context.Background()
+
+	first := unsubscribe(t, server, sdk, "first@gmail.com")
+	if n, err := feed.Poll(ctx); n != 1 || err != nil {
+		t.Fatalf("Poll() = %d, %v, want 1 event", n, err)
+	}
+	if got[0].UserId != first.Id || got[0].Email != "first@gmail.com" || got[0].ProjectId != 2 ||
+		got[0].SourceId != sendios.SourceSettings || got[0].Timestamp != now.Unix() {
+		t.Errorf("event = %+v", got[0])
+	}
+	if n, err := feed.Poll(ctx); n != 0 || err != nil {
+		t.Errorf("repeated Poll() = %d, %v, want no event", n, err)
+	}
+
+	// an unsubscribe in the same second as the checkpoint is still picked up
+	second := unsubscribe(t, server, sdk, "second@gmail.com")
+	*now = now.Add(time.Minute)
+	third := unsubscribe(t, server, sdk, "third@gmail.com")
+	if n, err := feed.Poll(ctx); n != 2 || err != nil {
+		t.Fatalf("Poll() = %d, %v, want 2 events", n, err)
+	}
+	if got[1].UserId != second.Id || got[2].UserId != third.Id {
+		t.Errorf("events = %+v", got)
+	}
+	if checkpoint := feed.Checkpoint(); checkpoint.Timestamp != now.Unix() || len(checkpoint.Seen) != 3 {
+		t.Errorf("checkpoint = %+v", checkpoint)
+	}
+
+	restarted, err := sdk.NewUnsubFeed(opts)
+	if err != nil {
+		t.Fatalf("NewUnsubFeed() error = %v", err)
+	}
+	if n, err := restarted.Poll(ctx); n != 0 || err != nil {
+		t.Errorf("Poll() after restart = %d, %v, want no event", n, err)
+	}
+}
+
+func TestUnsubFeed_RedeliversAfterHandlerError(t *testing.T) {
+	server, sdk, now := newUnsubFeedTestSdk()
+	defer server.Close()
+
+	unsubscribe(t, server, sdk, "first@gmail.com")
+	failing := unsubscribe(t, server, sdk, "second@gmail.com")
+
+	errRejected := errors.New("rejected")
+	var got []int
+	fail := true
+	feed, err := sdk.NewUnsubFeed(sendios.UnsubFeedOptions{
+		Since: *now,
+		Handler: func(ctx context.Context, event sendios.UnsubEvent) error {
+			if event.UserId == failing.Id && fail {
+				fail = false
+				return errRejected
+			}
+			got = append(got, event.UserId)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewUnsubFeed() error = %v", err)
+	}
+
+	if n, err := feed.Poll(context.Background()); n != 1 || !errors.Is(err, errRejected) {
+		t.Fatalf("Poll() = %d, %v, want 1 event and the handler error", n, err)
+	}
+	if n, err := feed.Poll(context.Background()); n != 1 || err != nil {
+		t.Fatalf("Poll() = %d, %v, want the failed event again", n, err)
+	}
+	if len(got) != 2 || got[1] != failing.Id {
+		t.Errorf("delivered = %v", got)
+	}
+}
+
+func TestUnsubFeed_RunDeliversToChannelUntilCancelled(t *testing.T) {
+	server, sdk, now := newUnsubFeedTestSdk()
+	defer server.Close()
+
+	if _, err := sdk.NewUnsubFeed(sendios.UnsubFeedOptions{}); err != sendios.ErrNoUnsubFeedHandler {
+		t.Errorf("NewUnsubFeed() without handler error = %v", err)
+	}
+
+	user := unsubscribe(t, server, sdk, "first@gmail.com")
+
+	events := make(chan sendios.UnsubEvent)
+	feed, err := sdk.NewUnsubFeed(sendios.UnsubFeedOptions{Since: *now, Interval: 10 * time.Millisecond, Events: events})
+	if err != nil {
+		t.Fatalf("NewUnsubFeed() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- feed.Run(ctx)
+	}()
+
+	select {
+	case event := <-events:
+		if event.UserId != user.Id {
+			t.Errorf("event = %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event delivered")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not stop")
+	}
+}
+
+func TestUnsubEvent_UnmarshalJSON(t *testing.T) {
+	date := time.Date(2021, 7, 5, 13, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		item    string
+		want    sendios.UnsubEvent
+		wantErr bool
+	}{
+		{"listed_timestamp", `{"user_id":5,"email":"test@gmail.com","project_id":2,"source_id":4,"date":"2021-07-05 13:00:00","timestamp":1625490000}`,
+			sendios.UnsubEvent{UserId: 5, Email: "test@gmail.com", ProjectId: 2, SourceId: 4, Date: "2021-07-05 13:00:00", Timestamp: date.Unix()}, false},
+		{"user_fields", `{"id":5,"email":"test@gmail.com","project_id":2,"created_at":"2021-07-05 13:00:00"}`,
+			sendios.UnsubEvent{UserId: 5, Email: "test@gmail.com", ProjectId: 2, Date: "2021-07-05 13:00:00", Timestamp: date.Unix()}, false},
+		{"no_date", `{"user_id":5}`, sendios.UnsubEvent{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got sendios.UnsubEvent
+			err := json.Unmarshal([]byte(tt.item), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if string(got.Raw) != tt.item {
+				t.Errorf("Raw = %s", got.Raw)
+			}
+			got.Raw = nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unmarshal() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnsubFeed_SkipsMalformedItems(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"_meta":{"status":"SUCCESS"},"data":[`+
+			`{"user_id":5,"email":"first@gmail.com","project_id":2,"timestamp":1625490000},`+
+			`{"user_id":6,"email":"broken@gmail.com","project_id":2,"date":"yesterday"},`+
+			`{"user_id":7,"email":"second@gmail.com","project_id":2,"timestamp":1625490001}]}`)
+	}))
+	defer ts.Close()
+
+	sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", sendios.WithApiV1BaseUrl(ts.URL), sendios.WithRetryPolicy(sendios.RetryPolicy{MaxAttempts: 1}))
+
+	events, err := sdk.UnsubscribesSince(context.Background(), 0)
+	if err != nil || len(events) != 2 || events[0].UserId != 5 || events[1].UserId != 7 {
+		t.Fatalf("UnsubscribesSince() = %+v, %v, want the valid items", events, err)
+	}
+
+	var got []int
+	var skipped []error
+	feed, err := sdk.NewUnsubFeed(sendios.UnsubFeedOptions{
+		Since: time.Unix(1625490000, 0),
+		Handler: func(ctx context.Context, event sendios.UnsubEvent) error {
+			got = append(got, event.UserId)
+			return nil
+		},
+		OnError: func(err error) {
+			skipped = append(skipped, err)
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewUnsubFeed() error = %v", err)
+	}
+
+	// the malformed item stays within the overlap, it must not stop the next polls
+	for i, want := range []int{2, 0} {
+		if n, err := feed.Poll(context.Background()); n != want || err != nil {
+			t.Fatalf("Poll() %d = %d, %v, want %d events", i, n, err, want)
+		}
+	}
+	if !reflect.DeepEqual(got, []int{5, 7}) || len(skipped) != 2 {
+		t.Errorf("delivered = %v, skipped = %v", got, skipped)
+	}
+	if checkpoint := feed.Checkpoint(); checkpoint.Timestamp != 1625490001 {
+		t.Errorf("checkpoint = %+v", checkpoint)
+	}
+}
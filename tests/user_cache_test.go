@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	sendios "github.com/sendios/go-sdk"
+	"github.com/sendios/go-sdk/sendiostest"
+)
+
+func countLookups(server *sendiostest.Server) int {
+	count := 0
+	for _, request := range server.Requests() {
+		if strings.HasPrefix(request.Route, "user/project/") {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestUserIdCache_ChainedLookups(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []sendios.Option
+		wantLookups int
+	}{
+		{"default_cache", nil, 1},
+		{"disabled", []sendios.Option{sendios.WithUserIdCache(nil)}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := sendiostest.NewServer()
+			defer server.Close()
+			user := server.AddUser(sendiostest.User{Email: "test@gmail.com", ProjectId: 2})
+
+			sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", append(server.Options(), tt.opts...)...)
+
+			if _, err := sdk.IsUnsubByEmailAndProjectId("test@gmail.com", 2); err != nil {
+				t.Fatalf("IsUnsubByEmailAndProjectId() error = %v", err)
+			}
+			if _, err := sdk.GetUnsubscribeReason("test@gmail.com", 2); err != nil {
+				t.Fatalf("GetUnsubscribeReason() error = %v", err)
+			}
+			if _, err := sdk.AddPaymentByEmailAndProjectId("test@gmail.com", 2, 1625479419, 1625479419, 1, 1, 100); err != nil {
+				t.Fatalf("AddPaymentByEmailAndProjectId() error = %v", err)
+			}
+
+			if got := countLookups(server); got != tt.wantLookups {
+				t.Errorf("lookups = %d, want %d", got, tt.wantLookups)
+			}
+			if payments := server.Payments(); len(payments) != 1 || payments[0].UserId != user.Id {
+				t.Errorf("payments = %+v", payments)
+			}
+		})
+	}
+}
+
+func TestUserIdCache_Invalidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		invalidate func(sdk *sendios.SendiosSdk, userId int) error
+	}{
+		{"subscribe", func(sdk *sendios.SendiosSdk, userId int) error {
+			_, err := sdk.SubscribeEmailUser(userId)
+			return err
+		}},
+		{"unsub_client", func(sdk *sendios.SendiosSdk, userId int) error {
+			_, err := sdk.UnsubEmailUserClient(userId)
+			return err
+		}},
+		{"unsub_admin", func(sdk *sendios.SendiosSdk, userId int) error {
+			_, err := sdk.UnsubEmailUserByAdmin("test@gmail.com", 2)
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := sendiostest.NewServer()
+			defer server.Close()
+			user := server.AddUser(sendiostest.User{Email: "test@gmail.com", ProjectId: 2})
+
+			sdk := sendios.NewSendiosSdk("3", "VeaGGspBXpGQeZGbfegEeq5PPJ2CsjQ6", server.Options()...)
+
+			if _, err := sdk.IsUnsubByEmailAndProjectId("test@gmail.com", 2); err != nil {
+				t.Fatalf("IsUnsubByEmailAndProjectId() error = %v", err)
+			}
+			if err := tt.invalidate(sdk, user.Id); err != nil {
+				t.Fatalf("invalidating call error = %v", err)
+			}
+			if _, err := sdk.IsUnsubByEmailAndProjectId("test@gmail.com", 2); err != nil {
+				t.Fatalf("IsUnsubByEmailAndProjectId() error = %v", err)
+			}
+
+			if got := countLookups(server); got != 2 {
+				t.Errorf("lookups = %d, want 2", got)
+			}
+		})
+	}
+}
+
+func TestUserIdLRUCache(t *testing.T) {
+	ctx := context.Background()
+
+	cache := sendios.NewUserIdLRUCache(2, 0)
+	cache.Set(ctx, 1, "a@gmail.com", 10)
+	cache.Set(ctx, 1, "b@gmail.com", 20)
+	cache.Get(ctx, 1, "a@gmail.com")
+	cache.Set(ctx, 2, "a@gmail.com", 30)
+
+	tests := []struct {
+		projectId int
+		email     string
+		wantId    int
+		wantOk    bool
+	}{
+		{1, "a@gmail.com", 10, true},
+		{1, "b@gmail.com", 0, false},
+		{2, "a@gmail.com", 30, true},
+	}
+	for _, tt := range tests {
+		if id, ok := cache.Get(ctx, tt.projectId, tt.email); id != tt.wantId || ok != tt.wantOk {
+			t.Errorf("Get(%d, %s) = %d, %v, want %d, %v", tt.projectId, tt.email, id, ok, tt.wantId, tt.wantOk)
+		}
+	}
+
+	cache.DeleteUser(ctx, 30)
+	if _, ok := cache.Get(ctx, 2, "a@gmail.com"); ok {
+		t.Error("Get() after DeleteUser() ok = true")
+	}
+
+	// the email now belongs to another user, forgetting the previous one keeps it
+	cache.Set(ctx, 1, "a@gmail.com", 11)
+	cache.DeleteUser(ctx, 10)
+	if id, ok := cache.Get(ctx, 1, "a@gmail.com"); id != 11 || !ok {
+		t.Errorf("Get() after DeleteUser() of the previous user = %d, %v", id, ok)
+	}
+	cache.DeleteUser(ctx, 11)
+	if _, ok := cache.Get(ctx, 1, "a@gmail.com"); ok {
+		t.Error("Get() after DeleteUser() of the new user ok = true")
+	}
+
+	// evicted entries leave nothing behind for DeleteUser
+	cache.Set(ctx, 3, "a@gmail.com", 40)
+	cache.Set(ctx, 3, "b@gmail.com", 50)
+	cache.Set(ctx, 3, "c@gmail.com", 60)
+	cache.DeleteUser(ctx, 40)
+	if id, ok := cache.Get(ctx, 3, "c@gmail.com"); id != 60 || !ok {
+		t.Errorf("Get() after DeleteUser() of an evicted user = %d, %v", id, ok)
+	}
+
+	expiring := sendios.NewUserIdLRUCache(2, 20*time.Millisecond)
+	expiring.Set(ctx, 1, "a@gmail.com", 10)
+	time.Sleep(40 * time.Millisecond)
+	if _, ok := expiring.Get(ctx, 1, "a@gmail.com"); ok {
+		t.Error("Get() after ttl ok = true")
+	}
+}
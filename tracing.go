@@ -0,0 +1,51 @@
+package go_sdk
+
+import (
+	"context"
+
+	"github.com/sendios/go-sdk/internal"
+)
+
+// SpanAttributes describe a traced call: operation such as SendEmail, http method,
+// route template such as unsub/admin/%d/email/%s and project id, 0 when there is none.
+type SpanAttributes = internal.SpanAttributes
+
+// Tracer starts a span around every call of the sdk, see WithTracer.
+type Tracer = internal.Tracer
+
+type Span = internal.Span
+
+// TraceHooks is a Tracer made of callbacks, either of which may be nil. The context returned
+// by OnStart is used for the call and passed to OnEnd.
+type TraceHooks struct {
+	OnStart func(ctx context.Context, attrs SpanAttributes) context.Context
+	OnEnd   func(ctx context.Context, attrs SpanAttributes, statusCode int, err error)
+}
+
+func (h TraceHooks) Start(ctx context.Context, attrs SpanAttributes) (context.Context, Span) {
+	if h.OnStart != nil {
+		ctx = h.OnStart(ctx, attrs)
+	}
+
+	return ctx, hookSpan{ctx: ctx, attrs: attrs, onEnd: h.OnEnd}
+}
+
+type hookSpan struct {
+	ctx   context.Context
+	attrs SpanAttributes
+	onEnd func(ctx context.Context, attrs SpanAttributes, statusCode int, err error)
+}
+
+func (s hookSpan) End(statusCode int, err error) {
+	if s.onEnd != nil {
+		s.onEnd(s.ctx, s.attrs, statusCode, err)
+	}
+}
+
+// ContextWithTraceparent makes the sdk send the W3C traceparent with every request made
+// with the returned context. A Tracer may replace it with the traceparent of its span.
+// Values which are not valid traceparents are ignored.
+func ContextWithTraceparent(ctx context.Context, traceparent string) context.Context {
+
+	return internal.ContextWithTraceparent(ctx, traceparent)
+}
@@ -0,0 +1,212 @@
+package go_sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// The methods below wrap the raw api methods and decode their responses.
+// Responses with the ERROR meta status are returned as *APIError.
+
+func (sdk *SendiosSdk) EmailUserById(ctx context.Context, id int) (*EmailUser, error) {
+	res, err := sdk.GetEmailUserByIdCtx(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var data emailUserData
+	if _, err := DecodeResponse(res, &data); err != nil {
+		return nil, err
+	}
+
+	return &data.User, nil
+}
+
+func (sdk *SendiosSdk) EmailUserByEmailAndProjectId(ctx context.Context, email string, projectId int) (*EmailUser, error) {
+	res, err := sdk.GetEmailUserByEmailAndProjectIdCtx(ctx, email, projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	var data emailUserData
+	if _, err := DecodeResponse(res, &data); err != nil {
+		return nil, err
+	}
+
+	return &data.User, nil
+}
+
+func (sdk *SendiosSdk) PushUserById(ctx context.Context, userId int) (*PushUser, error) {
+	res, err := sdk.GetPushUserByIdCtx(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var data pushUserData
+	if _, err := DecodeResponse(res, &data); err != nil {
+		return nil, err
+	}
+
+	return &data.PushUser, nil
+}
+
+func (sdk *SendiosSdk) PushUserByProjectIdAndHash(ctx context.Context, projectId int, hash string) (*PushUser, error) {
+	res, err := sdk.GetPushUserByProjectIdAndHashCtx(ctx, projectId, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	var data pushUserData
+	if _, err := DecodeResponse(res, &data); err != nil {
+		return nil, err
+	}
+
+	return &data.PushUser, nil
+}
+
+func (sdk *SendiosSdk) UnsubStatusByUserId(ctx context.Context, userId int) (*UnsubStatus, error) {
+	res, err := sdk.IsUnsubUserCtx(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var status UnsubStatus
+	if _, err := DecodeResponse(res, &status); err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+func (sdk *SendiosSdk) UnsubStatusByEmailAndProjectId(ctx context.Context, email string, projectId int) (*UnsubStatus, error) {
+	res, err := sdk.IsUnsubByEmailAndProjectIdCtx(ctx, email, projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	var status UnsubStatus
+	if _, err := DecodeResponse(res, &status); err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+func (sdk *SendiosSdk) UnsubTypesByEmailUserId(ctx context.Context, userId int) ([]UnsubType, error) {
+	res, err := sdk.GetUnsubListByEmailUserIdCtx(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var types []UnsubType
+	if _, err := DecodeResponse(res, &types); err != nil {
+		return nil, err
+	}
+
+	return types, nil
+}
+
+func (sdk *SendiosSdk) UserFieldsByUserId(ctx context.Context, userId int) (*UserFields, error) {
+	res, err := sdk.GetUserFieldsByUserIdCtx(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var data userFieldsData
+	if _, err := DecodeResponse(res, &data); err != nil {
+		return nil, err
+	}
+
+	return &data.UserFields, nil
+}
+
+func (sdk *SendiosSdk) UserFieldsByEmailAndProjectId(ctx context.Context, email string, projectId int) (*UserFields, error) {
+	res, err := sdk.GetUserFieldsByEmailAndProjectIdCtx(ctx, email, projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	var data userFieldsData
+	if _, err := DecodeResponse(res, &data); err != nil {
+		return nil, err
+	}
+
+	return &data.UserFields, nil
+}
+
+func (sdk *SendiosSdk) CheckEmailResult(ctx context.Context, email string, sanitize bool) (*CheckEmailResult, error) {
+	res, err := sdk.CheckEmailCtx(ctx, email, sanitize)
+	if err != nil {
+		return nil, err
+	}
+
+	var result CheckEmailResult
+	if _, err := DecodeResponse(res, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (sdk *SendiosSdk) BuyingDecision(ctx context.Context, email string) (*BuyingDecision, error) {
+	res, err := sdk.GetBuyingDecisionsCtx(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	var decision BuyingDecision
+	if _, err := DecodeResponse(res, &decision); err != nil {
+		return nil, err
+	}
+
+	return &decision, nil
+}
+
+// UnsubscribesSince returns the users unsubscribed at or after the unix time since. Listed items
+// which can not be decoded are skipped and logged, see WithLogger.
+func (sdk *SendiosSdk) UnsubscribesSince(ctx context.Context, since int64) ([]UnsubEvent, error) {
+	events, skipped, err := sdk.unsubscribesSince(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, err := range skipped {
+		sdk.logSkippedUnsubscribe(ctx, err)
+	}
+
+	return events, nil
+}
+
+// unsubscribesSince decodes the listed items one by one, so a malformed item does not hide the
+// others. The errors of the skipped items are returned apart.
+func (sdk *SendiosSdk) unsubscribesSince(ctx context.Context, since int64) ([]UnsubEvent, []error, error) {
+	res, err := sdk.GetUnsubscribesByDateCtx(ctx, since)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var items []json.RawMessage
+	if _, err := DecodeResponse(res, &items); err != nil {
+		return nil, nil, err
+	}
+
+	events := make([]UnsubEvent, 0, len(items))
+	var skipped []error
+	for i, item := range items {
+		var event UnsubEvent
+		if err := json.Unmarshal(item, &event); err != nil {
+			skipped = append(skipped, fmt.Errorf("error while decoding unsubscribe %d of %d: %w", i+1, len(items), err))
+			continue
+		}
+		events = append(events, event)
+	}
+
+	return events, skipped, nil
+}
+
+func (sdk *SendiosSdk) logSkippedUnsubscribe(ctx context.Context, err error) {
+	if sdk.Request.Logger != nil {
+		sdk.Request.Logger.Log(ctx, LogLevelWarn, "sendios unsubscribe skipped", "error", err)
+	}
+}
@@ -0,0 +1,310 @@
+package go_sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sendios/go-sdk/internal"
+)
+
+const (
+	DefaultUnsubFeedInterval = time.Minute
+	DefaultUnsubFeedOverlap  = 5 * time.Minute
+)
+
+var ErrNoUnsubFeedHandler = errors.New("unsubscribe feed needs a handler, an events channel or a suppression list")
+
+// UnsubCheckpoint is the position of an UnsubFeed. Seen holds the keys of the events delivered
+// within the overlap before Timestamp, so events fetched again are not delivered twice.
+type UnsubCheckpoint struct {
+	Timestamp int64            `json:"timestamp"`
+	Seen      map[string]int64 `json:"seen,omitempty"`
+}
+
+// UnsubCheckpointStore persists the checkpoint of an UnsubFeed between runs. Load returns the
+// zero checkpoint when nothing was saved yet.
+type UnsubCheckpointStore interface {
+	Load() (UnsubCheckpoint, error)
+	Save(checkpoint UnsubCheckpoint) error
+}
+
+// FileCheckpointStore keeps the checkpoint as json in a file, replaced atomically on every save.
+type FileCheckpointStore struct {
+	Path string
+}
+
+func (s *FileCheckpointStore) Load() (UnsubCheckpoint, error) {
+	var checkpoint UnsubCheckpoint
+
+	data, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return checkpoint, fmt.Errorf("error while reading checkpoint: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return checkpoint, fmt.Errorf("error while decoding checkpoint: %w", err)
+	}
+
+	return checkpoint, nil
+}
+
+func (s *FileCheckpointStore) Save(checkpoint UnsubCheckpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("error while encoding checkpoint: %w", err)
+	}
+
+	return internal.WriteFileAtomic(s.Path, data)
+}
+
+type UnsubFeedOptions struct {
+	// Checkpoint persists the position of the feed, it is only kept in memory when nil.
+	Checkpoint UnsubCheckpointStore
+	// Since is where the feed starts when there is no checkpoint yet, the current time when zero.
+	// Like every poll, the first one also looks back by Overlap.
+	Since time.Time
+	// Interval is the time between two polls of Run, DefaultUnsubFeedInterval when not positive.
+	Interval time.Duration
+	// Overlap is how far before the checkpoint every poll looks again, to pick up unsubscribes
+	// listed late. DefaultUnsubFeedOverlap when not positive.
+	Overlap time.Duration
+	// Handler receives every event. A returned error stops the poll, the event and the ones
+	// after it are delivered again by the next poll. Handler and Events may both be nil when
+	// the sdk has a suppression list, see WithSuppressionList, which is updated before every
+	// event is delivered.
+	Handler func(ctx context.Context, event UnsubEvent) error
+	// Events receives every event when Handler is nil. An event counts as delivered once it
+	// is received; the channel is never closed by the feed.
+	Events chan<- UnsubEvent
+	// OnError is called with the error of a failed poll, after which Run keeps polling.
+	// Run returns the error instead when nil. Listed items which can not be decoded are
+	// skipped, so they never stop the feed, and reported to OnError, or logged when nil.
+	OnError func(err error)
+}
+
+// UnsubFeed polls GetUnsubscribesByDate and delivers every unsubscribe once it is listed.
+// Delivery is at least once: the checkpoint only moves past events that were delivered, and
+// is saved at the end of every poll, so events delivered before a crash may be delivered again.
+type UnsubFeed struct {
+	sdk        *SendiosSdk
+	store      UnsubCheckpointStore
+	interval   time.Duration
+	overlap    int64
+	handler    func(ctx context.Context, event UnsubEvent) error
+	onError    func(err error)
+	mu         sync.Mutex
+	checkpoint UnsubCheckpoint
+}
+
+// NewUnsubFeed loads the checkpoint from opts.Checkpoint, starting at opts.Since without one.
+func (sdk *SendiosSdk) NewUnsubFeed(opts UnsubFeedOptions) (*UnsubFeed, error) {
+	handler := opts.Handler
+	if handler == nil && opts.Events != nil {
+		handler = sendUnsubEvent(opts.Events)
+	}
+	if handler == nil && sdk.suppressions != nil {
+		handler = func(ctx context.Context, event UnsubEvent) error {
+			return nil
+		}
+	}
+	if handler == nil {
+		return nil, ErrNoUnsubFeedHandler
+	}
+
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultUnsubFeedInterval
+	}
+	overlap := opts.Overlap
+	if overlap <= 0 {
+		overlap = DefaultUnsubFeedOverlap
+	}
+
+	var checkpoint UnsubCheckpoint
+	if opts.Checkpoint != nil {
+		var err error
+		checkpoint, err = opts.Checkpoint.Load()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if checkpoint.Timestamp == 0 {
+		since := opts.Since
+		if since.IsZero() {
+			since = time.Now()
+		}
+		checkpoint.Timestamp = since.Unix()
+	}
+	if checkpoint.Seen == nil {
+		checkpoint.Seen = map[string]int64{}
+	}
+
+	return &UnsubFeed{
+		sdk:        sdk,
+		store:      opts.Checkpoint,
+		interval:   interval,
+		overlap:    int64(overlap / time.Second),
+		handler:    handler,
+		onError:    opts.OnError,
+		checkpoint: checkpoint,
+	}, nil
+}
+
+// Checkpoint returns a copy of the current position of the feed.
+func (f *UnsubFeed) Checkpoint() UnsubCheckpoint {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	seen := make(map[string]int64, len(f.checkpoint.Seen))
+	for key, timestamp := range f.checkpoint.Seen {
+		seen[key] = timestamp
+	}
+
+	return UnsubCheckpoint{Timestamp: f.checkpoint.Timestamp, Seen: seen}
+}
+
+// Poll fetches the unsubscribes since the checkpoint, minus the overlap, and delivers the ones
+// not delivered yet in the order they happened. It returns the number of delivered events.
+func (f *UnsubFeed) Poll(ctx context.Context) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	since := f.checkpoint.Timestamp - f.overlap
+	if since < 0 {
+		since = 0
+	}
+
+	events, skipped, err := f.sdk.unsubscribesSince(ctx, since)
+	if err != nil {
+		return 0, fmt.Errorf("error while fetching unsubscribes: %w", err)
+	}
+	for _, err := range skipped {
+		f.skip(ctx, err)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp < events[j].Timestamp
+	})
+
+	fresh := events[:0]
+	for _, event := range events {
+		if _, ok := f.checkpoint.Seen[unsubEventKey(event)]; !ok {
+			fresh = append(fresh, event)
+		}
+	}
+	if err := f.suppress(fresh); err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, event := range fresh {
+		key := unsubEventKey(event)
+		if err := f.handler(ctx, event); err != nil {
+			if saveErr := f.save(); saveErr != nil {
+				return delivered, saveErr
+			}
+			return delivered, fmt.Errorf("error while delivering unsubscribe event: %w", err)
+		}
+
+		f.checkpoint.Seen[key] = event.Timestamp
+		if event.Timestamp > f.checkpoint.Timestamp {
+			f.checkpoint.Timestamp = event.Timestamp
+		}
+		delivered++
+	}
+
+	return delivered, f.save()
+}
+
+// Run polls every interval until ctx is done. It returns nil once ctx is done, after the
+// progress of the interrupted poll is saved.
+func (f *UnsubFeed) Run(ctx context.Context) error {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := f.Poll(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if f.onError == nil {
+				return err
+			}
+			f.onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// skip reports an item of the list which could not be decoded.
+func (f *UnsubFeed) skip(ctx context.Context, err error) {
+	if f.onError != nil {
+		f.onError(err)
+		return
+	}
+
+	f.sdk.logSkippedUnsubscribe(ctx, err)
+}
+
+// suppress adds the events to the suppression list of the sdk, if any, before they are
+// delivered. The list is saved once for the whole poll.
+func (f *UnsubFeed) suppress(events []UnsubEvent) error {
+	if f.sdk.suppressions == nil || len(events) == 0 {
+		return nil
+	}
+
+	suppressions := make([]Suppression, 0, len(events))
+	for _, event := range events {
+		suppressions = append(suppressions, Suppression{UserId: event.UserId, ProjectId: event.ProjectId, Email: event.Email, All: true})
+	}
+	if err := f.sdk.suppressions.Add(suppressions...); err != nil {
+		return fmt.Errorf("error while updating suppression list: %w", err)
+	}
+
+	return nil
+}
+
+// save drops the keys which fell out of the overlap and persists the checkpoint.
+func (f *UnsubFeed) save() error {
+	oldest := f.checkpoint.Timestamp - f.overlap
+	for key, timestamp := range f.checkpoint.Seen {
+		if timestamp < oldest {
+			delete(f.checkpoint.Seen, key)
+		}
+	}
+
+	if f.store == nil {
+		return nil
+	}
+
+	return f.store.Save(f.checkpoint)
+}
+
+func unsubEventKey(event UnsubEvent) string {
+	return fmt.Sprintf("%d/%d/%d", event.UserId, event.ProjectId, event.Timestamp)
+}
+
+func sendUnsubEvent(events chan<- UnsubEvent) func(ctx context.Context, event UnsubEvent) error {
+	return func(ctx context.Context, event UnsubEvent) error {
+		select {
+		case events <- event:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
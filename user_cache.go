@@ -0,0 +1,126 @@
+package go_sdk
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sendios/go-sdk/internal"
+)
+
+const (
+	DefaultUserIdCacheSize = 10000
+	DefaultUserIdCacheTTL  = time.Minute * 5
+)
+
+// UserIdCache stores the email user ids resolved by IsUnsubByEmailAndProjectId,
+// GetUnsubscribeReason and AddPaymentByEmailAndProjectId, so repeated calls for the same
+// email skip the user lookup. Implementations backed by a shared store such as Redis should
+// treat failures as misses, the cache is only an optimisation.
+type UserIdCache interface {
+	Get(ctx context.Context, projectId int, email string) (userId int, ok bool)
+	Set(ctx context.Context, projectId int, email string, userId int)
+	Delete(ctx context.Context, projectId int, email string)
+	// DeleteUser removes the entries of a user, whatever its email.
+	DeleteUser(ctx context.Context, userId int)
+}
+
+// NewUserIdLRUCache returns the in-memory UserIdCache used by default, holding at most size
+// entries for ttl.
+func NewUserIdLRUCache(size int, ttl time.Duration) UserIdCache {
+
+	return &userIdLRUCache{cache: internal.NewLRUCache(size, ttl)}
+}
+
+type userIdLRUCache struct {
+	cache *internal.LRUCache
+}
+
+func (c *userIdLRUCache) Get(ctx context.Context, projectId int, email string) (int, bool) {
+	userId, ok := c.cache.Get(userIdCacheKey(projectId, email))
+	if !ok {
+		return 0, false
+	}
+
+	return userId.(int), true
+}
+
+func (c *userIdLRUCache) Set(ctx context.Context, projectId int, email string, userId int) {
+	c.cache.SetTagged(userIdCacheKey(projectId, email), userId, userIdCacheTag(userId))
+}
+
+func (c *userIdLRUCache) Delete(ctx context.Context, projectId int, email string) {
+	c.cache.Delete(userIdCacheKey(projectId, email))
+}
+
+func (c *userIdLRUCache) DeleteUser(ctx context.Context, userId int) {
+	c.cache.DeleteTag(userIdCacheTag(userId))
+}
+
+// email returns the email a user id was cached for, the first one when there are several.
+func (c *userIdLRUCache) email(userId int) (int, string, bool) {
+	for _, key := range c.cache.TaggedKeys(userIdCacheTag(userId)) {
+		parts := strings.SplitN(key, "/", 2)
+		if projectId, err := strconv.Atoi(parts[0]); err == nil && len(parts) == 2 {
+			return projectId, parts[1], true
+		}
+	}
+
+	return 0, "", false
+}
+
+func userIdCacheKey(projectId int, email string) string {
+	return fmt.Sprintf("%d/%s", projectId, email)
+}
+
+func userIdCacheTag(userId int) string {
+	return fmt.Sprintf("user/%d", userId)
+}
+
+// resolveUserId returns the id of the email user, from the cache when possible, or
+// ErrUserNotFound when the lookup found no user.
+func (sdk *SendiosSdk) resolveUserId(ctx context.Context, email string, projectId int) (int, error) {
+	if sdk.userIds != nil {
+		if userId, ok := sdk.userIds.Get(ctx, projectId, email); ok {
+			return userId, nil
+		}
+	}
+
+	res, err := sdk.GetEmailUserByEmailAndProjectIdCtx(ctx, email, projectId)
+	if IsNotFound(err) {
+		return 0, &notFoundError{err: ErrUserNotFound, cause: err}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var data emailUserData
+	meta, err := decodeEnvelope(res, &data)
+	if err != nil {
+		return 0, fmt.Errorf("error while email user parsing: %w", err)
+	}
+	// the api also reports a missing user with a 200 and the ERROR status, or without a user
+	if meta.Status == MetaStatusError || data.User.Id == 0 {
+		return 0, &notFoundError{err: ErrUserNotFound}
+	}
+
+	if sdk.userIds != nil {
+		sdk.userIds.Set(ctx, projectId, email, data.User.Id)
+	}
+
+	return data.User.Id, nil
+}
+
+func (sdk *SendiosSdk) forgetUser(ctx context.Context, userId int) {
+	if sdk.userIds != nil {
+		sdk.userIds.DeleteUser(ctx, userId)
+	}
+}
+
+func (sdk *SendiosSdk) forgetEmail(ctx context.Context, projectId int, email string) {
+	if sdk.userIds != nil {
+		sdk.userIds.Delete(ctx, projectId, email)
+	}
+}